@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ionut-t/notes/internal/config"
 	"github.com/ionut-t/notes/note"
 	"github.com/ionut-t/notes/ui"
 	"github.com/spf13/cobra"
@@ -14,19 +20,102 @@ func newAddCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add",
 		Short: "Add a new note",
-		Long:  `Add a new note to your collection.`,
+		Long: `Add a new note to your collection.
+With --stdin the note is created from the standard input without opening the editor,
+named with --name or after the header on its first line.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			store := note.NewStore()
-			runAddUI(store)
+			store := newStore()
+
+			if fromStdin, _ := cmd.Flags().GetBool("stdin"); fromStdin {
+				name, _ := cmd.Flags().GetString("name")
+				addFromStdin(store, name)
+				return
+			}
+
+			templateName, _ := cmd.Flags().GetString("template")
+
+			var template string
+			if templateName != "" {
+				var err error
+				if template, err = store.GetTemplate(templateName); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+			}
+
+			runAddUI(store, template)
 		},
 	}
 
+	cmd.Flags().StringP("template", "t", "", "Start the note from a template in the templates directory")
+	cmd.Flags().Bool("stdin", false, "Create the note from the standard input, without the editor")
+	cmd.Flags().StringP("name", "n", "", "Name of the note created with --stdin, instead of its first header")
+
 	return cmd
 }
 
-func runAddUI(store *note.Store) {
+// addFromStdin creates a note with everything read from the standard input
+// and prints its name, which gets a suffix when the name is taken
+func addFromStdin(store *note.Store, name string) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Println("Error reading stdin:", err)
+		os.Exit(1)
+	}
+
+	content := string(data)
+	if strings.TrimSpace(content) == "" {
+		fmt.Println("Nothing to add, the standard input is empty")
+		os.Exit(1)
+	}
+
+	name = strings.Join(strings.Fields(name), "-")
+	if name == "" {
+		title, ok := note.NameFromHeader(content)
+
+		if template := config.GetNameTemplate(); template != "" {
+			name = note.ExpandNameTemplate(template, title, time.Now())
+		}
+
+		if name == "" && !ok {
+			fmt.Println("Give the note a --name or start it with a header")
+			os.Exit(1)
+		}
+
+		name = cmp.Or(name, title)
+	}
+
+	if _, err := store.LoadNotes(); err != nil {
+		fmt.Println("Error loading notes:", err)
+		os.Exit(1)
+	}
+
+	if err := store.Create(name, content); err != nil && !errors.Is(err, note.ErrAutoCommit) {
+		fmt.Println("Error creating note:", err)
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+	}
+
+	// the created note is only known to the store once loaded
+	if _, err := store.LoadNotes(); err != nil {
+		fmt.Println("Error loading notes:", err)
+		os.Exit(1)
+	}
+
+	current, _ := store.GetCurrentNote()
+	fmt.Println(current.Name)
+}
+
+func runAddUI(store *note.Store, template string) {
 	store.LoadNotes()
-	p := tea.NewProgram(ui.NewAddModel(store))
+
+	m := ui.NewAddModel(store)
+	if template != "" {
+		m.SetTemplate(template)
+	}
+
+	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running UI: %v\n", err)
 		os.Exit(1)
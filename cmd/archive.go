@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ionut-t/notes/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func archiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "List or restore archived notes",
+		Long:  `Print the archived notes with their last modified date, or move one back among the notes with --restore.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			restore, _ := cmd.Flags().GetString("restore")
+
+			store := newStore()
+
+			if _, err := store.LoadNotes(); err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			if restore != "" {
+				if err := store.Unarchive(restore); err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				current, _ := store.GetCurrentNote()
+				fmt.Printf("Restored %q\n", current.Name)
+				return
+			}
+
+			archived := store.ListArchived()
+			if len(archived) == 0 {
+				fmt.Println("No archived notes")
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, n := range archived {
+				fmt.Fprintf(w, "%s\t%s\n", n.Name, n.UpdatedAt.Format(config.GetDateFormat()))
+			}
+			_ = w.Flush()
+		},
+	}
+
+	cmd.Flags().String("restore", "", "Move the named note out of the archive")
+	_ = cmd.RegisterFlagCompletionFunc("restore", completeArchivedNames)
+
+	return cmd
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/ionut-t/notes/note"
+	"github.com/spf13/cobra"
+)
+
+// completeNoteNames completes a note name argument. It only lists file
+// names, so it stays fast no matter how large the notes are.
+func completeNoteNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names, err := note.NewStore().Names()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	matches := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			matches = append(matches, name)
+		}
+	}
+
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeArchivedNames completes the name of an archived note
+func completeArchivedNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var matches []string
+
+	for _, n := range note.NewStore().ListArchived() {
+		if strings.HasPrefix(n.Name, toComplete) {
+			matches = append(matches, n.Name)
+		}
+	}
+
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
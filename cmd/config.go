@@ -21,6 +21,7 @@ func configCmd() *cobra.Command {
 			// Check if flags were provided
 			editorFlag, _ := cmd.Flags().GetString("editor")
 			storageFlag, _ := cmd.Flags().GetString("storage")
+			dateFormatFlag, _ := cmd.Flags().GetString("date-format")
 
 			// Handle flag updates
 			flagsSet := false
@@ -39,6 +40,16 @@ func configCmd() *cobra.Command {
 				fmt.Println("Storage set to:", storageFlag)
 			}
 
+			if dateFormatFlag != "" {
+				if err := config.SetDateFormat(dateFormatFlag); err != nil {
+					fmt.Println("Error setting date format:", err)
+					os.Exit(1)
+				}
+
+				flagsSet = true
+				fmt.Println("Date format set to:", dateFormatFlag)
+			}
+
 			// Write config if any flags were set
 			if flagsSet {
 				if err := viper.WriteConfig(); err != nil {
@@ -56,6 +67,7 @@ func configCmd() *cobra.Command {
 
 	cmd.Flags().StringP("editor", "e", "", "Set the editor to use for notes")
 	cmd.Flags().StringP("storage", "s", "", "Set the storage path for notes")
+	cmd.Flags().String("date-format", "", "Set the Go time layout used to display dates, e.g. \"01/02/2006 3:04PM\"")
 
 	return cmd
 }
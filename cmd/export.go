@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ionut-t/notes/note"
+	"github.com/spf13/cobra"
+)
+
+// export formats supported by the export command
+const (
+	exportCSV      = "csv"
+	exportMarkdown = "markdown"
+	exportJSON     = "json"
+)
+
+func exportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [output]",
+		Short: "Export notes",
+		Long: `Export notes to a file, or to stdout when no output file is given.
+
+Formats:
+  csv       the note list with its metadata
+  markdown  every note in a single file, under its name as a header
+  json      every note with its content and timestamps`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			format, _ := cmd.Flags().GetString("format")
+			asCSV, _ := cmd.Flags().GetBool("csv")
+			tag, _ := cmd.Flags().GetString("tag")
+			prefix, _ := cmd.Flags().GetString("prefix")
+
+			if asCSV {
+				format = exportCSV
+			}
+
+			if format == "" {
+				fmt.Println("No export format selected, use --format csv, markdown or json")
+				os.Exit(1)
+			}
+
+			if !slices.Contains([]string{exportCSV, exportMarkdown, exportJSON}, format) {
+				fmt.Printf("Unknown export format %q, expected csv, markdown or json\n", format)
+				os.Exit(1)
+			}
+
+			store := newStore()
+
+			notes, err := store.LoadNotes()
+			if err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			notes = filterByPrefix(filterByTag(notes, tag), prefix)
+
+			var out io.Writer = os.Stdout
+			var file *os.File
+
+			if len(args) == 1 {
+				file, err = os.Create(args[0])
+				if err != nil {
+					fmt.Println("Error creating export file:", err)
+					os.Exit(1)
+				}
+
+				out = file
+			}
+
+			switch format {
+			case exportCSV:
+				err = writeCSV(out, collectMetadata(notes))
+			case exportMarkdown:
+				err = writeMarkdownBundle(out, notes)
+			case exportJSON:
+				err = writeJSON(out, notes)
+			}
+
+			if file != nil {
+				if closeErr := file.Close(); err == nil {
+					err = closeErr
+				}
+			}
+
+			if err != nil {
+				fmt.Println("Error exporting notes:", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().String("format", "", "Export format: csv, markdown or json")
+	cmd.Flags().Bool("csv", false, "Export the note list as CSV, same as --format csv")
+	cmd.Flags().String("tag", "", "Only export notes with the given tag")
+	cmd.Flags().String("prefix", "", "Only export notes whose name starts with the given prefix")
+
+	return cmd
+}
+
+// noteMetadata is the exported summary of a note
+type noteMetadata struct {
+	Name     string    `json:"name"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+	Words    int       `json:"words"`
+	Tags     []string  `json:"tags"`
+}
+
+func collectMetadata(notes []note.Note) []noteMetadata {
+	metadata := make([]noteMetadata, len(notes))
+
+	for i, n := range notes {
+		metadata[i] = noteMetadata{
+			Name:     n.Name,
+			Created:  n.CreatedAt,
+			Modified: n.UpdatedAt,
+			Words:    note.WordCount(n.Body()),
+			Tags:     n.Tags,
+		}
+	}
+
+	return metadata
+}
+
+func filterByTag(notes []note.Note, tag string) []note.Note {
+	if tag == "" {
+		return notes
+	}
+
+	filtered := make([]note.Note, 0, len(notes))
+	for _, n := range notes {
+		if n.HasTag(tag) {
+			filtered = append(filtered, n)
+		}
+	}
+
+	return filtered
+}
+
+func filterByPrefix(notes []note.Note, prefix string) []note.Note {
+	if prefix == "" {
+		return notes
+	}
+
+	filtered := make([]note.Note, 0, len(notes))
+	for _, n := range notes {
+		if strings.HasPrefix(n.Name, prefix) {
+			filtered = append(filtered, n)
+		}
+	}
+
+	return filtered
+}
+
+// writeMarkdownBundle writes the body of every note under its name
+// as a header, separated by horizontal rules
+func writeMarkdownBundle(out io.Writer, notes []note.Note) error {
+	for i, n := range notes {
+		if i > 0 {
+			if _, err := io.WriteString(out, "\n---\n\n"); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(out, "# %s\n\n%s\n", n.Name, n.Body()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(out io.Writer, notes []note.Note) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(notes)
+}
+
+func writeCSV(out io.Writer, metadata []noteMetadata) error {
+	w := csv.NewWriter(out)
+
+	if err := w.Write([]string{"name", "created", "modified", "words", "tags"}); err != nil {
+		return err
+	}
+
+	for _, m := range metadata {
+		err := w.Write([]string{
+			m.Name,
+			formatExportTime(m.Created),
+			formatExportTime(m.Modified),
+			strconv.Itoa(m.Words),
+			strings.Join(m.Tags, ", "),
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
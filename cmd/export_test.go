@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCSV_Escaping(t *testing.T) {
+	t.Parallel()
+
+	modified := time.Date(2025, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		metadata noteMetadata
+		expected string
+	}{
+		{
+			name:     "plain",
+			metadata: noteMetadata{Name: "groceries", Modified: modified, Words: 3, Tags: []string{"home"}},
+			expected: "groceries,,2025-03-01T10:00:00Z,3,home\n",
+		},
+		{
+			name:     "commas",
+			metadata: noteMetadata{Name: "milk, eggs", Words: 2, Tags: []string{"home", "shopping"}},
+			expected: "\"milk, eggs\",,,2,\"home, shopping\"\n",
+		},
+		{
+			name:     "quotes",
+			metadata: noteMetadata{Name: `the "plan"`, Tags: []string{`say "hi"`}},
+			expected: "\"the \"\"plan\"\"\",,,0,\"say \"\"hi\"\"\"\n",
+		},
+		{
+			name:     "newlines",
+			metadata: noteMetadata{Name: "first\nsecond", Words: 1},
+			expected: "\"first\nsecond\",,,1,\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			assert.NoError(t, writeCSV(&out, []noteMetadata{tt.metadata}))
+
+			assert.Equal(t, "name,created,modified,words,tags\n"+tt.expected, out.String())
+
+			records, err := csv.NewReader(&out).ReadAll()
+			assert.NoError(t, err)
+			assert.Len(t, records, 2)
+			assert.Equal(t, tt.metadata.Name, records[1][0], "The name should read back unchanged")
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ionut-t/notes/note"
+	"github.com/ionut-t/notes/styles"
+	"github.com/spf13/cobra"
+)
+
+func grepCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grep <pattern>",
+		Short: "Search the content of the notes",
+		Long: `Print every line of the notes matching the pattern as note:line: text.
+The pattern is matched literally unless --regex is given. Exits with status 1 when nothing matches.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			useRegex, _ := cmd.Flags().GetBool("regex")
+			ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+			namesOnly, _ := cmd.Flags().GetBool("files-with-matches")
+
+			pattern, err := compilePattern(args[0], useRegex, ignoreCase)
+			if err != nil {
+				fmt.Println("Invalid pattern:", err)
+				os.Exit(1)
+			}
+
+			store := newStore()
+
+			notes, err := store.LoadNotes()
+			if err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			notes = slices.Clone(notes)
+			note.SortNotes(notes, note.SortByName)
+
+			if !grepNotes(notes, pattern, namesOnly) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("regex", false, "Treat the pattern as a regular expression")
+	cmd.Flags().BoolP("ignore-case", "i", false, "Match regardless of case")
+	cmd.Flags().BoolP("files-with-matches", "l", false, "Only print the names of the matching notes")
+
+	return cmd
+}
+
+func compilePattern(pattern string, useRegex, ignoreCase bool) (*regexp.Regexp, error) {
+	if !useRegex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// grepNotes prints the matching lines, or only the names of the
+// matching notes, and reports whether anything matched
+func grepNotes(notes []note.Note, pattern *regexp.Regexp, namesOnly bool) bool {
+	found := false
+
+	for _, n := range notes {
+		for i, line := range strings.Split(n.Content, "\n") {
+			if !pattern.MatchString(line) {
+				continue
+			}
+
+			found = true
+
+			if namesOnly {
+				fmt.Println(n.Name)
+				break
+			}
+
+			highlighted := pattern.ReplaceAllStringFunc(line, func(match string) string {
+				return styles.Highlight.Render(match)
+			})
+
+			fmt.Printf("%s:%d: %s\n", n.Name, i+1, highlighted)
+		}
+	}
+
+	return found
+}
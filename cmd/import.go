@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ionut-t/notes/internal/config"
+	"github.com/ionut-t/notes/internal/utils"
+	"github.com/ionut-t/notes/note"
+	"github.com/spf13/cobra"
+)
+
+func importCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <dir|glob>...",
+		Short: "Import markdown files as notes",
+		Long: `Copy the markdown files of the given directories, or matching the given globs, into the notes,
+each named after its file. Markdown files are those with the extension of the notes, .md unless
+the extension option is set. Other files are skipped, except text files with --txt,
+and so are files already in the notes storage.
+Name collisions are handled as set by --collision or the import_collision option.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			includeTxt, _ := cmd.Flags().GetBool("txt")
+			move, _ := cmd.Flags().GetBool("move")
+			collision, _ := cmd.Flags().GetString("collision")
+			if collision == "" {
+				collision = config.GetImportCollisionPolicy()
+			}
+
+			policy, err := note.ParseCollisionPolicy(collision)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			extension, err := config.GetExtension()
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			paths, skipped, err := collectImportFiles(args, config.GetStorage(), extension, includeTxt)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			store := newStore()
+
+			if _, err := store.LoadNotes(); err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			imported, failed := 0, 0
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, path := range paths {
+				result := store.Import(path, policy)
+
+				switch result.Action {
+				case note.ImportFailed:
+					failed++
+					fmt.Fprintf(w, "%s\t%s\t%v\n", result.Action, path, result.Err)
+					continue
+
+				case note.ImportSkipped:
+					skipped++
+					fmt.Fprintf(w, "%s\t%s\n", result.Action, path)
+					continue
+				}
+
+				imported++
+				fmt.Fprintf(w, "%s\t%s\t%s\n", result.Action, path, result.Name)
+
+				// the note was imported, only its auto-commit failed
+				if result.Err != nil {
+					fmt.Fprintf(w, "warning\t%s\t%v\n", path, result.Err)
+				}
+
+				if move {
+					if err := os.Remove(path); err != nil {
+						fmt.Fprintf(w, "failed\t%s\tcould not remove the original: %v\n", path, err)
+					}
+				}
+			}
+			_ = w.Flush()
+
+			fmt.Printf("\nImported %d %s, skipped %d %s\n",
+				imported, utils.Ternary(imported == 1, "note", "notes"),
+				skipped, utils.Ternary(skipped == 1, "file", "files"))
+
+			if failed > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("txt", false, "Also import .txt files")
+	cmd.Flags().Bool("move", false, "Delete the original files once imported")
+	cmd.Flags().String("collision", "", "What to do when a note has the same name: dedupe, skip or overwrite (default import_collision)")
+
+	return cmd
+}
+
+// collectImportFiles lists the files of the directories and globs that can be
+// imported, counting the other files it skips. Files already in the storage
+// are skipped, since importing them would copy or, with --move, delete notes.
+func collectImportFiles(args []string, storage, extension string, includeTxt bool) ([]string, int, error) {
+	var paths []string
+	skipped := 0
+
+	for _, arg := range args {
+		var candidates []string
+
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			entries, err := os.ReadDir(arg)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					candidates = append(candidates, filepath.Join(arg, entry.Name()))
+				}
+			}
+		} else {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid pattern %q: %w", arg, err)
+			}
+
+			if len(matches) == 0 {
+				return nil, 0, fmt.Errorf("no files match %s", arg)
+			}
+
+			candidates = matches
+		}
+
+		for _, path := range candidates {
+			ext := strings.ToLower(filepath.Ext(path))
+
+			if ext != strings.ToLower(extension) && (!includeTxt || ext != ".txt") {
+				skipped++
+				continue
+			}
+
+			if isInsideDir(path, storage) {
+				skipped++
+				continue
+			}
+
+			if !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	return paths, skipped, nil
+}
+
+// isInsideDir reports whether path is dir itself or somewhere below it
+func isInsideDir(path, dir string) bool {
+	path, dir = resolvePath(path), resolvePath(dir)
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolvePath makes path absolute and resolves its symlinks when it exists
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	return path
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectImportFiles_Extension(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "b.markdown", "c.MARKDOWN", "d.txt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("content"), 0644))
+	}
+
+	paths, skipped, err := collectImportFiles([]string{dir}, t.TempDir(), ".markdown", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "b.markdown"), filepath.Join(dir, "c.MARKDOWN")}, paths)
+	assert.Equal(t, 2, skipped)
+
+	paths, skipped, err = collectImportFiles([]string{dir}, t.TempDir(), ".md", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.md"), filepath.Join(dir, "d.txt")}, paths)
+	assert.Equal(t, 2, skipped)
+}
+
+func TestCollectImportFiles_SkipsStorage(t *testing.T) {
+	t.Parallel()
+
+	storage := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(storage, "work"), 0755))
+	for _, name := range []string{"a.md", filepath.Join("work", "b.md")} {
+		assert.NoError(t, os.WriteFile(filepath.Join(storage, name), []byte("content"), 0644))
+	}
+
+	paths, skipped, err := collectImportFiles([]string{storage, filepath.Join(storage, "work")}, storage, ".md", false)
+	assert.NoError(t, err)
+	assert.Empty(t, paths, "Notes should never be imported onto themselves")
+	assert.Equal(t, 2, skipped)
+
+	paths, _, err = collectImportFiles([]string{storage}, filepath.Join(storage, "work"), ".md", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(storage, "a.md")}, paths)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/spf13/cobra"
+)
+
+func keysCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "keys",
+		Short: "Print all keybindings",
+		Long:  `Print every keybinding grouped by the part of the UI it applies to.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Print(renderKeys(keymap.Contexts()))
+		},
+	}
+}
+
+func renderKeys(contexts []keymap.Context) string {
+	keyWidth := 0
+	for _, context := range contexts {
+		for _, binding := range context.Bindings {
+			keyWidth = max(keyWidth, len([]rune(binding.Help().Key)))
+		}
+	}
+
+	var sb strings.Builder
+
+	for i, context := range contexts {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString(context.Name + "\n")
+
+		for _, binding := range context.Bindings {
+			sb.WriteString(formatBinding(binding, keyWidth))
+		}
+	}
+
+	return sb.String()
+}
+
+func formatBinding(binding key.Binding, keyWidth int) string {
+	help := binding.Help()
+	padding := strings.Repeat(" ", keyWidth-len([]rune(help.Key)))
+
+	return fmt.Sprintf("  %s%s  %s\n", help.Key, padding, help.Desc)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/ionut-t/notes/note"
+	"github.com/spf13/cobra"
+)
+
+func lsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "Print note names",
+		Long:  `Print the name of every note, one per line, for use in scripts and pipes.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			sortFlag, _ := cmd.Flags().GetString("sort")
+			tag, _ := cmd.Flags().GetString("tag")
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			order, err := note.ParseSortOrder(sortFlag)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			store := newStore()
+
+			notes, err := store.LoadNotes()
+			if err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			// sort a copy so the store keeps its own order
+			notes = slices.Clone(filterByTag(notes, tag))
+			note.SortNotes(notes, order)
+
+			names := make([]string, len(notes))
+			for i, n := range notes {
+				names[i] = n.Name
+			}
+
+			if asJSON {
+				if err := json.NewEncoder(os.Stdout).Encode(names); err != nil {
+					fmt.Println("Error encoding note names:", err)
+					os.Exit(1)
+				}
+
+				return
+			}
+
+			for _, name := range names {
+				fmt.Println(name)
+			}
+		},
+	}
+
+	cmd.Flags().String("sort", string(note.SortByModified), "Sort by modified, modified-asc, name, name-desc or created")
+	cmd.Flags().String("tag", "", "Only list notes with the given tag")
+	cmd.Flags().Bool("json", false, "Print the names as a JSON array")
+
+	return cmd
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func openCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "open <name>",
+		Short:             "Open a note",
+		Long:              `Open the notes manager with the given note, or one of its aliases, selected.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNoteNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			store := newStore()
+
+			if _, err := store.LoadNotes(); err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			name, ok := store.ResolveName(args[0])
+			if !ok {
+				fmt.Printf("Note %q not found\n", args[0])
+				os.Exit(1)
+			}
+
+			store.SetCurrentNoteName(name)
+			runManagerUI(store)
+		},
+	}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func randomCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "random",
+		Short: "Open a random note",
+		Long:  `Open the notes manager with a randomly picked note selected.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			store := newStore()
+
+			if _, err := store.LoadNotes(); err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			randomNote, ok := store.Random()
+			if !ok {
+				fmt.Println("No notes found")
+				return
+			}
+
+			store.SetCurrentNoteName(randomNote.Name)
+			runManagerUI(store)
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ionut-t/notes/internal/utils"
+	"github.com/ionut-t/notes/note"
+	"github.com/spf13/cobra"
+)
+
+func renameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <name> <new-name>",
+		Short: "Rename a note, or find and replace in every note name",
+		Long: `Rename a note, or with --all replace <find> with <replace> in the name of every note.
+Names colliding with another note get a counter. Use --dry-run to print the renames without applying them.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeNoteNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			all, _ := cmd.Flags().GetBool("all")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			store := newStore()
+
+			if _, err := store.LoadNotes(); err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			if !all {
+				if dryRun {
+					fmt.Println("--dry-run only applies to --all")
+					os.Exit(1)
+				}
+
+				renamed, err := store.RenameNote(args[0], args[1])
+				if err != nil && !errors.Is(err, note.ErrAutoCommit) {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
+				fmt.Printf("Renamed %q to %q\n", args[0], renamed.Name)
+				return
+			}
+
+			renames, err := store.RenameAll(args[0], args[1], dryRun)
+			for _, r := range renames {
+				fmt.Printf("%s -> %s\n", r.From, r.To)
+			}
+
+			if err != nil && !errors.Is(err, note.ErrAutoCommit) {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("%s %d %s\n",
+				utils.Ternary(dryRun, "Would rename", "Renamed"),
+				len(renames), utils.Ternary(len(renames) == 1, "note", "notes"))
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "Replace <find> with <replace> in the name of every note")
+	cmd.Flags().Bool("dry-run", false, "Print the renames of --all without applying them")
+
+	return cmd
+}
@@ -8,7 +8,8 @@ import (
 	"time"
 
 	"github.com/ionut-t/notes/internal/config"
-	"github.com/ionut-t/notes/note"
+	"github.com/ionut-t/notes/styles"
+	"github.com/ionut-t/notes/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -19,13 +20,7 @@ var (
 	date    = "unknown"
 )
 
-const logo = "  \033[31m" + `
-   _   _    ___    _____   _____   ____  
-  | \ | |  / _ \  |_   _| | ____| / ___| 
-  |  \| | | | | |   | |   |  _|   \___ \ 
-  | |\  | | |_| |   | |   | |___   ___) |
-  |_| \_|  \___/    |_|   |_____| |____/                  	 
-` + "\033[0m"
+const logo = "  \033[31m" + ui.Logo + "\033[0m"
 
 var rootCmd = &cobra.Command{
 	Use:     "notes",
@@ -33,7 +28,7 @@ var rootCmd = &cobra.Command{
 	Long:    `A simple CLI tool for managing notes`,
 	Version: version,
 	Run: func(cmd *cobra.Command, args []string) {
-		store := note.NewStore()
+		store := newStore()
 		runManagerUI(store)
 	},
 }
@@ -41,6 +36,18 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	rootCmd.AddCommand(configCmd())
 	rootCmd.AddCommand(newAddCmd())
+	rootCmd.AddCommand(keysCmd())
+	rootCmd.AddCommand(randomCmd())
+	rootCmd.AddCommand(exportCmd())
+	rootCmd.AddCommand(lsCmd())
+	rootCmd.AddCommand(openCmd())
+	rootCmd.AddCommand(todayCmd())
+	rootCmd.AddCommand(serveCmd())
+	rootCmd.AddCommand(statsCmd())
+	rootCmd.AddCommand(archiveCmd())
+	rootCmd.AddCommand(grepCmd())
+	rootCmd.AddCommand(importCmd())
+	rootCmd.AddCommand(renameCmd())
 
 	err := rootCmd.Execute()
 	if err != nil {
@@ -86,11 +93,19 @@ func init() {
 	rootCmd.SetVersionTemplate(versionTemplate)
 }
 
+// storageFlag is the storage to use for this run instead of the configured one
+var storageFlag string
+
+// profileFlag is the profile whose storage is used, remembered for the next runs
+var profileFlag string
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	var cfgFile string
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "set-config", "", "config file (default is $HOME/.notes/.config.toml)")
+	rootCmd.PersistentFlags().StringVar(&storageFlag, "storage", "", "use another notes directory for this run")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "use the notes directory of a profile from the [profiles] config")
 
 }
 
@@ -98,4 +113,40 @@ func initConfig() {
 	if _, err := config.InitialiseConfigFile(); err != nil {
 		fmt.Printf("Error initializing config: %v\n", err)
 	}
+
+	switch {
+	case storageFlag != "":
+		if err := config.OverrideStorage(storageFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting storage: %v\n", err)
+			os.Exit(1)
+		}
+
+	case profileFlag != "":
+		if err := config.UseProfile(profileFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting profile: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := config.SetProfile(profileFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remember the profile: %v\n", err)
+		}
+
+	case config.GetProfile() != "":
+		if err := config.UseProfile(config.GetProfile()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v, using the storage instead\n", err)
+		}
+	}
+
+	if config.GetNoColor() {
+		styles.DisableColor()
+	}
+
+	palette, err := config.GetPalette()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load palette: %v\n", err)
+	}
+
+	for _, warning := range styles.ApplyPalette(palette) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", warning)
+	}
 }
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/ionut-t/notes/note"
+	"github.com/spf13/cobra"
+)
+
+// maxRequestSize bounds a single request line, which holds the whole note on create
+const maxRequestSize = 10 * 1024 * 1024
+
+// errRequestTooLarge answers a request line longer than maxRequestSize
+var errRequestTooLarge = fmt.Errorf("request larger than %d bytes", maxRequestSize)
+
+// serveRequest is a single line read from stdin
+type serveRequest struct {
+	ID      any    `json:"id,omitempty"`
+	Cmd     string `json:"cmd"`
+	Name    string `json:"name,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// serveResponse is written to stdout for every request.
+// The id of the request is echoed back so clients can match responses.
+type serveResponse struct {
+	ID     any    `json:"id,omitempty"`
+	OK     bool   `json:"ok"`
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// noteContent is the result of the get command
+type noteContent struct {
+	noteMetadata
+	Content string `json:"content"`
+}
+
+type serveCommand struct {
+	description string
+	run         func(store *note.Store, req serveRequest) (any, error)
+}
+
+// serveCommands is the registry of the commands understood by notes serve
+var serveCommands = map[string]serveCommand{
+	"list": {
+		description: "List the metadata of every note",
+		run: func(store *note.Store, req serveRequest) (any, error) {
+			return collectMetadata(store.GetNotes()), nil
+		},
+	},
+	"get": {
+		description: "Get a note by name or alias, with its content",
+		run: func(store *note.Store, req serveRequest) (any, error) {
+			n, err := findNote(store, req.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			return noteContent{
+				noteMetadata: collectMetadata([]note.Note{n})[0],
+				Content:      n.Content,
+			}, nil
+		},
+	},
+	"create": {
+		description: "Create a note from name and content, returning the name it was saved as",
+		run: func(store *note.Store, req serveRequest) (any, error) {
+			if req.Name == "" {
+				return nil, errors.New("name is required")
+			}
+
+			// the note is created even when it can't be committed, so a
+			// client retrying on an error would create it a second time
+			createErr := store.Create(req.Name, req.Content)
+			if createErr != nil && !errors.Is(createErr, note.ErrAutoCommit) {
+				return nil, createErr
+			}
+
+			if _, err := store.LoadNotes(); err != nil {
+				return nil, err
+			}
+
+			created, _ := store.GetCurrentNote()
+
+			result := map[string]string{"name": created.Name}
+			if createErr != nil {
+				result["warning"] = createErr.Error()
+			}
+
+			return result, nil
+		},
+	},
+	"delete": {
+		description: "Delete a note by name or alias",
+		run: func(store *note.Store, req serveRequest) (any, error) {
+			n, err := findNote(store, req.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			return nil, store.Delete(n.Name)
+		},
+	},
+}
+
+// help is registered separately since it lists the registry itself
+func init() {
+	serveCommands["help"] = serveCommand{
+		description: "Describe the available commands",
+		run: func(store *note.Store, req serveRequest) (any, error) {
+			commands := make(map[string]string, len(serveCommands))
+			for name, command := range serveCommands {
+				commands[name] = command.description
+			}
+
+			return commands, nil
+		},
+	}
+}
+
+func serveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Answer JSON commands on stdin",
+		Long: `Read line-delimited JSON commands on stdin and write one JSON response per line on stdout,
+so that editors and scripts can use the notes without the UI.
+
+Each request is an object such as {"cmd":"get","name":"todo"}. Send {"cmd":"help"} to list the commands.
+Responses look like {"ok":true,"result":...} or {"ok":false,"error":"..."}.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := serve(newStore(), os.Stdin, os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "Error serving commands:", err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// serve answers every request read from in until it is closed
+func serve(store *note.Store, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReaderSize(in, 64*1024)
+	encoder := json.NewEncoder(out)
+
+	for {
+		line, err := readRequestLine(reader)
+
+		var response serveResponse
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case errors.Is(err, errRequestTooLarge):
+			response = serveResponse{Error: err.Error()}
+		case err != nil:
+			return err
+		case len(line) == 0:
+			continue
+		default:
+			response = handleRequest(store, line)
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return err
+		}
+	}
+}
+
+// readRequestLine reads the next line without its line ending. A line longer
+// than maxRequestSize is read to its end and dropped with errRequestTooLarge,
+// so that the requests following it are still answered.
+func readRequestLine(reader *bufio.Reader) ([]byte, error) {
+	var line []byte
+	tooLarge := false
+
+	for {
+		chunk, err := reader.ReadSlice('\n')
+
+		switch {
+		case tooLarge:
+			// the rest of a dropped line
+		case len(line)+len(chunk) > maxRequestSize+len("\r\n"):
+			tooLarge, line = true, nil
+		default:
+			line = append(line, chunk...)
+		}
+
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+
+		if tooLarge {
+			return nil, errRequestTooLarge
+		}
+
+		// the last line may not end with a newline
+		if err != nil && len(line) == 0 {
+			return nil, err
+		}
+
+		return bytes.TrimRight(line, "\r\n"), nil
+	}
+}
+
+func handleRequest(store *note.Store, line []byte) serveResponse {
+	var req serveRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return serveResponse{Error: fmt.Sprintf("malformed request: %v", err)}
+	}
+
+	command, ok := serveCommands[req.Cmd]
+	if !ok {
+		names := make([]string, 0, len(serveCommands))
+		for name := range serveCommands {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		return serveResponse{ID: req.ID, Error: fmt.Sprintf("unknown command %q, expected one of %v", req.Cmd, names)}
+	}
+
+	// reload on every request so changes made elsewhere, deleted notes
+	// included, are picked up
+	if _, err := store.LoadNotes(); err != nil {
+		return serveResponse{ID: req.ID, Error: err.Error()}
+	}
+
+	result, err := command.run(store, req)
+	if err != nil {
+		return serveResponse{ID: req.ID, Error: err.Error()}
+	}
+
+	return serveResponse{ID: req.ID, OK: true, Result: result}
+}
+
+func findNote(store *note.Store, nameOrAlias string) (note.Note, error) {
+	if nameOrAlias == "" {
+		return note.Note{}, errors.New("name is required")
+	}
+
+	name, ok := store.ResolveName(nameOrAlias)
+	if !ok {
+		return note.Note{}, fmt.Errorf("note %q not found", nameOrAlias)
+	}
+
+	store.SetCurrentNoteName(name)
+
+	n, _ := store.GetCurrentNote()
+
+	return n, nil
+}
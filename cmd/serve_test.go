@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ionut-t/notes/note"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveLines sends the requests to a server over the notes in storage
+// and decodes its responses, one per answered line
+func serveLines(t *testing.T, storage string, requests ...string) []serveResponse {
+	t.Helper()
+
+	var out strings.Builder
+	in := strings.NewReader(strings.Join(requests, "\n"))
+
+	assert.NoError(t, serve(note.NewStoreWithConfig(storage, "vim"), in, &out))
+
+	var responses []serveResponse
+
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var response serveResponse
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &response))
+		responses = append(responses, response)
+	}
+
+	return responses
+}
+
+func TestServe_Commands(t *testing.T) {
+	t.Parallel()
+
+	storage := t.TempDir()
+
+	responses := serveLines(t, storage,
+		`{"id":1,"cmd":"create","name":"todo","content":"# Todo\n\n- milk"}`,
+		`{"id":2,"cmd":"get","name":"todo"}`,
+		`{"id":3,"cmd":"list"}`,
+		``,
+		`{"id":4,"cmd":"delete","name":"todo"}`,
+		`{"id":5,"cmd":"get","name":"todo"}`,
+	)
+
+	assert.Len(t, responses, 5, "Empty lines should be skipped")
+
+	for _, response := range responses[:4] {
+		assert.True(t, response.OK, response.Error)
+	}
+
+	assert.Equal(t, float64(1), responses[0].ID)
+	assert.Equal(t, map[string]any{"name": "todo"}, responses[0].Result)
+
+	content := responses[1].Result.(map[string]any)
+	assert.Equal(t, "# Todo\n\n- milk", content["content"])
+
+	assert.Len(t, responses[2].Result, 1)
+
+	assert.False(t, responses[4].OK)
+	assert.Equal(t, `note "todo" not found`, responses[4].Error)
+	assert.Equal(t, float64(5), responses[4].ID)
+}
+
+func TestServe_Errors(t *testing.T) {
+	t.Parallel()
+
+	responses := serveLines(t, t.TempDir(),
+		`{"cmd":`,
+		`{"id":"a","cmd":"nope"}`,
+		`{"id":"b","cmd":"create"}`,
+		`{"id":"c","cmd":"help"}`,
+	)
+
+	assert.Len(t, responses, 4)
+
+	assert.False(t, responses[0].OK)
+	assert.Contains(t, responses[0].Error, "malformed request")
+
+	assert.False(t, responses[1].OK)
+	assert.Equal(t, "a", responses[1].ID)
+	assert.Contains(t, responses[1].Error, `unknown command "nope"`)
+
+	assert.False(t, responses[2].OK)
+	assert.Equal(t, "name is required", responses[2].Error)
+
+	assert.True(t, responses[3].OK)
+	assert.Contains(t, responses[3].Result, "create")
+}
+
+func TestServe_ChangesMadeElsewhere(t *testing.T) {
+	t.Parallel()
+
+	storage := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(storage, "gone.md"), []byte("content"), 0644))
+
+	store := note.NewStoreWithConfig(storage, "vim")
+
+	assert.True(t, handleRequest(store, []byte(`{"cmd":"get","name":"gone"}`)).OK)
+
+	assert.NoError(t, os.Remove(filepath.Join(storage, "gone.md")))
+
+	response := handleRequest(store, []byte(`{"cmd":"get","name":"gone"}`))
+	assert.False(t, response.OK, "Notes deleted outside the server should no longer be found")
+
+	response = handleRequest(store, []byte(`{"cmd":"delete","name":"gone"}`))
+	assert.False(t, response.OK)
+}
+
+func TestServe_RequestTooLarge(t *testing.T) {
+	t.Parallel()
+
+	large := `{"cmd":"create","name":"big","content":"` + strings.Repeat("a", maxRequestSize) + `"}`
+
+	responses := serveLines(t, t.TempDir(),
+		large,
+		`{"id":1,"cmd":"list"}`,
+		large,
+	)
+
+	assert.Len(t, responses, 3, "The server should keep answering after a request too large")
+
+	assert.False(t, responses[0].OK)
+	assert.Equal(t, errRequestTooLarge.Error(), responses[0].Error)
+
+	assert.True(t, responses[1].OK)
+	assert.Equal(t, float64(1), responses[1].ID)
+
+	assert.False(t, responses[2].OK, "A last line without a newline should be checked too")
+}
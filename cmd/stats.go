@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/ionut-t/notes/internal/config"
+	"github.com/ionut-t/notes/note"
+	"github.com/spf13/cobra"
+)
+
+func statsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Print statistics about the notes",
+		Long:  `Print the number of notes, their words and size on disk, the oldest and newest notes and how many notes use each tag.`,
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			store := newStore()
+
+			notes, err := store.LoadNotes()
+			if err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			stats := note.ComputeStats(notes)
+
+			if asJSON {
+				if err := json.NewEncoder(os.Stdout).Encode(stats); err != nil {
+					fmt.Println("Error encoding stats:", err)
+					os.Exit(1)
+				}
+
+				return
+			}
+
+			writeStats(os.Stdout, stats)
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Print the stats as JSON")
+
+	return cmd
+}
+
+func writeStats(out io.Writer, stats note.Stats) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Notes\t%d\n", stats.Notes)
+	fmt.Fprintf(w, "Words\t%d\n", stats.Words)
+	fmt.Fprintf(w, "Size on disk\t%s\n", formatSize(stats.Size))
+	fmt.Fprintf(w, "Average words\t%d\n", stats.AverageWords)
+
+	dateFormat := config.GetDateFormat()
+
+	if stats.Oldest != nil {
+		fmt.Fprintf(w, "Oldest\t%s (%s)\n", stats.Oldest.Name, stats.Oldest.UpdatedAt.Format(dateFormat))
+	}
+
+	if stats.Newest != nil {
+		fmt.Fprintf(w, "Newest\t%s (%s)\n", stats.Newest.Name, stats.Newest.UpdatedAt.Format(dateFormat))
+	}
+
+	if len(stats.Tags) > 0 {
+		fmt.Fprintln(w, "\nTags")
+
+		// the most used tags first, then alphabetically
+		tags := slices.SortedFunc(maps.Keys(stats.Tags), func(a, b string) int {
+			if c := cmp.Compare(stats.Tags[b], stats.Tags[a]); c != 0 {
+				return c
+			}
+
+			return cmp.Compare(a, b)
+		})
+
+		for _, tag := range tags {
+			fmt.Fprintf(w, "  %s\t%d\n", tag, stats.Tags[tag])
+		}
+	}
+
+	_ = w.Flush()
+}
+
+// formatSize formats a number of bytes with the largest fitting unit
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	units := []string{"KB", "MB", "GB"}
+
+	for i, u := range units {
+		value /= unit
+		if value < unit || i == len(units)-1 {
+			return fmt.Sprintf("%.1f %s", value, u)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/ionut-t/notes/internal/config"
+	"github.com/ionut-t/notes/note"
+)
+
+// passphraseEnv holds the passphrase of encrypted notes, for scripts
+// and for the commands reading from stdin
+const passphraseEnv = "NOTES_PASSPHRASE"
+
+// newStore creates the store of the notes, unlocked with the passphrase
+// when encryption is enabled. It exits when they can't be unlocked.
+func newStore() *note.Store {
+	// notes saved with the wrong extension would be missing from the next run
+	if _, err := config.GetExtension(); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	store := note.NewStore()
+
+	if !config.GetEncryption() {
+		return store
+	}
+
+	if err := unlock(store); err != nil {
+		fmt.Println("Error unlocking notes:", err)
+		os.Exit(1)
+	}
+
+	return store
+}
+
+// unlock reads the passphrase from the environment or asks for it,
+// twice when it's being set for the first time
+func unlock(store *note.Store) error {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return store.Unlock(passphrase)
+	}
+
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return fmt.Errorf("encryption is enabled, set %s to unlock the notes without a terminal", passphraseEnv)
+	}
+
+	passphrase, err := readPassphrase("Passphrase: ")
+	if err != nil {
+		return err
+	}
+
+	if !store.HasPassphrase() {
+		repeated, err := readPassphrase("Repeat the passphrase: ")
+		if err != nil {
+			return err
+		}
+
+		if repeated != passphrase {
+			return errors.New("the passphrases don't match")
+		}
+	}
+
+	return store.Unlock(passphrase)
+}
+
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	passphrase, err := term.ReadPassword(os.Stdin.Fd())
+
+	return string(passphrase), err
+}
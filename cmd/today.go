@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ionut-t/notes/internal/config"
+	"github.com/ionut-t/notes/note"
+	"github.com/spf13/cobra"
+)
+
+// dailyTemplate is the template used to seed daily notes, when it exists
+const dailyTemplate = "daily"
+
+func todayCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "today",
+		Aliases: []string{"journal"},
+		Short:   "Open today's note",
+		Long: `Open the note named after today's date, creating it first if needed.
+The name follows name_template when it's set, without a title.
+New daily notes start from the "daily" template when there is one.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			store := newStore()
+
+			templateName := ""
+			if store.HasTemplate(dailyTemplate) {
+				templateName = dailyTemplate
+			}
+
+			// without a name template, daily notes are named after the date
+			name := note.ExpandNameTemplate(config.GetNameTemplate(), "", time.Now())
+			if name == "" {
+				name = time.Now().Format(time.DateOnly)
+			}
+
+			if _, err := store.CreateFromTemplate(name, templateName); err != nil && !errors.Is(err, note.ErrAutoCommit) {
+				fmt.Println("Error creating today's note:", err)
+				os.Exit(1)
+			} else if err != nil {
+				fmt.Fprintln(os.Stderr, "Warning:", err)
+			}
+
+			if _, err := store.LoadNotes(); err != nil {
+				fmt.Println("Error loading notes:", err)
+				os.Exit(1)
+			}
+
+			runManagerUI(store)
+		},
+	}
+}
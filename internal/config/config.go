@@ -4,12 +4,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 const notesDir = ".notes"
 
+const defaultMinListWidth = 50
+
+const defaultSpellcheckDictionary = "/usr/share/dict/words"
+
+const defaultDateFormat = "02/01/2006 15:04"
+
+// DefaultExtension is the extension of the note files unless configured otherwise
+const DefaultExtension = ".md"
+
+const defaultAutosaveInterval = 30 * time.Second
+
+// defaultTabWidth matches the tab stops of the editor
+const defaultTabWidth = 4
+
 func getDefaultEditor() string {
 	if editor := os.Getenv("EDITOR"); editor != "" {
 		return editor
@@ -32,7 +48,69 @@ func GetEditor() string {
 	return editor
 }
 
+// storageOverride is the storage set for this run with --storage
+var storageOverride string
+
+// OverrideStorage makes GetStorage return the path until the process exits,
+// without saving it to the config file
+func OverrideStorage(path string) error {
+	path, err := expandHome(path)
+	if err != nil {
+		return err
+	}
+
+	storageOverride = path
+
+	return nil
+}
+
+// GetProfiles returns the storage of each profile of the [profiles] table, by name
+func GetProfiles() map[string]string {
+	return viper.GetStringMapString("profiles")
+}
+
+// GetProfile returns the last profile used, empty when none was
+func GetProfile() string {
+	return viper.GetString("profile")
+}
+
+// GetProfileStorage returns the storage of the profile, with a leading "~/"
+// expanded. Profile names are case insensitive, as are all config keys.
+func GetProfileStorage(name string) (string, error) {
+	storage, ok := GetProfiles()[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("unknown profile %s", name)
+	}
+
+	return expandHome(storage)
+}
+
+// UseProfile makes GetStorage return the storage of the profile until the process exits
+func UseProfile(name string) error {
+	storage, err := GetProfileStorage(name)
+	if err != nil {
+		return err
+	}
+
+	return OverrideStorage(storage)
+}
+
+// SetProfile remembers the profile as the last one used
+func SetProfile(name string) error {
+	if _, err := InitialiseConfigFile(); err != nil {
+		return err
+	}
+
+	viper.Set("profile", strings.ToLower(name))
+
+	return viper.WriteConfig()
+}
+
 func GetStorage() string {
+	if storageOverride != "" {
+		return storageOverride
+	}
+
 	storage := viper.GetString("storage")
 
 	if storage != "" {
@@ -54,6 +132,210 @@ func GetStorage() string {
 	return dir
 }
 
+// GetExtension returns the extension of the note files, which
+// has to start with a dot such as ".md" or ".txt"
+func GetExtension() (string, error) {
+	extension := viper.GetString("extension")
+	if extension == "" {
+		return DefaultExtension, nil
+	}
+
+	if len(extension) < 2 || !strings.HasPrefix(extension, ".") || strings.ContainsAny(extension, `/\`) {
+		return "", fmt.Errorf("invalid extension %q, expected a dot followed by the extension such as \".txt\"", extension)
+	}
+
+	return extension, nil
+}
+
+// GetMinListWidth returns the width below which the split view
+// collapses into a single list
+func GetMinListWidth() int {
+	if width := viper.GetInt("min_list_width"); width > 0 {
+		return width
+	}
+
+	return defaultMinListWidth
+}
+
+// GetSplitRatio returns the fraction of the split view taken by the list,
+// 0 when unset or out of range so that the list keeps min_list_width
+func GetSplitRatio() float64 {
+	if ratio := viper.GetFloat64("split_ratio"); ratio > 0 && ratio < 1 {
+		return ratio
+	}
+
+	return 0
+}
+
+// GetImportCollisionPolicy returns how name collisions are handled
+// when importing notes: dedupe, skip or overwrite
+func GetImportCollisionPolicy() string {
+	return viper.GetString("import_collision")
+}
+
+// GetNumberHeaders reports whether headers are rendered with outline numbering
+func GetNumberHeaders() bool {
+	return viper.GetBool("number_headers")
+}
+
+// GetClickableLinks reports whether links are rendered as OSC 8 hyperlinks,
+// which only some terminals support
+func GetClickableLinks() bool {
+	return viper.GetBool("clickable_links")
+}
+
+// GetRelativeTime reports whether the last modified times are
+// shown relative to now, such as "2 hours ago"
+func GetRelativeTime() bool {
+	return viper.GetBool("relative_time")
+}
+
+// GetConfirmDelete reports whether deleting notes asks for a confirmation,
+// which it does unless confirm_delete is set to false
+func GetConfirmDelete() bool {
+	if !viper.IsSet("confirm_delete") {
+		return true
+	}
+
+	return viper.GetBool("confirm_delete")
+}
+
+// GetCodeThemes returns the Chroma styles overriding the theme
+// for the code blocks of a language, by language
+func GetCodeThemes() map[string]string {
+	return viper.GetStringMapString("code_themes")
+}
+
+// GetEncryption reports whether the notes are encrypted on disk
+// with a key derived from a passphrase asked for on startup
+func GetEncryption() bool {
+	return viper.GetBool("encryption")
+}
+
+// GetGitAutoCommit reports whether changes to the notes are committed
+// when the storage is a git repository
+func GetGitAutoCommit() bool {
+	return viper.GetBool("git_auto_commit")
+}
+
+// GetAutosaveInterval returns how often the unsaved changes of the edited
+// note are written to a draft. A zero or negative interval disables it.
+func GetAutosaveInterval() time.Duration {
+	if !viper.IsSet("autosave_interval") {
+		return defaultAutosaveInterval
+	}
+
+	return time.Duration(viper.GetInt("autosave_interval")) * time.Second
+}
+
+// GetTabWidth returns the columns between the tab stops
+// of the code blocks in the rendered notes
+func GetTabWidth() int {
+	if width := viper.GetInt("tab_width"); width > 0 {
+		return width
+	}
+
+	return defaultTabWidth
+}
+
+// GetTabsToSpaces reports whether the tabs of the notes are
+// replaced with spaces up to the tab stops when they're saved
+func GetTabsToSpaces() bool {
+	return viper.GetBool("tabs_to_spaces")
+}
+
+// GetNameTemplate returns the template new notes left unnamed are named
+// after, a Go time layout with a {title} placeholder. It's empty unless set.
+func GetNameTemplate() string {
+	return viper.GetString("name_template")
+}
+
+// GetFocusLine returns the row of the rendered note highlighted to keep
+// track of the reading position, 1 being the top row and 0 disabling it
+func GetFocusLine() int {
+	return max(viper.GetInt("focus_line"), 0)
+}
+
+// GetMaxRenderWidth returns the width the rendered notes wrap at
+// when the pane is wider, 0 to wrap them at the pane width
+func GetMaxRenderWidth() int {
+	return max(viper.GetInt("max_render_width"), 0)
+}
+
+// GetNoColor reports whether the output is left unstyled, with the no_color
+// option or the NO_COLOR environment variable (https://no-color.org)
+func GetNoColor() bool {
+	return viper.GetBool("no_color") || os.Getenv("NO_COLOR") != ""
+}
+
+// GetLineNumbers returns which lines of the rendered note get
+// line numbers: off, all, code or prose
+func GetLineNumbers() string {
+	return viper.GetString("line_numbers")
+}
+
+// GetSpellcheck reports whether misspelled words are underlined in the rendered view
+func GetSpellcheck() bool {
+	return viper.GetBool("spellcheck")
+}
+
+// GetSpellcheckDictionary returns the wordlist used by the spellcheck
+func GetSpellcheckDictionary() string {
+	if path := viper.GetString("spellcheck_dictionary"); path != "" {
+		return path
+	}
+
+	return defaultSpellcheckDictionary
+}
+
+// GetSpellcheckIgnore returns the extra words the spellcheck accepts
+func GetSpellcheckIgnore() []string {
+	return viper.GetStringSlice("spellcheck_ignore")
+}
+
+// GetPalette reads the colour overrides from the palette file set in the
+// config, if any. The file can be TOML or JSON and maps colour names to values.
+func GetPalette() (map[string]string, error) {
+	path := viper.GetString("palette")
+	if path == "" {
+		return nil, nil
+	}
+
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	palette := make(map[string]string)
+	for name, value := range v.AllSettings() {
+		palette[name] = fmt.Sprint(value)
+	}
+
+	return palette, nil
+}
+
+// expandHome replaces a leading "~/" of the path with the home directory
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, rest), nil
+}
+
 func SetEditor(editor string) error {
 	if _, err := InitialiseConfigFile(); err != nil {
 		return err
@@ -68,20 +350,83 @@ func SetEditor(editor string) error {
 	return viper.WriteConfig()
 }
 
+// GetDateFormat returns the Go time layout used to display dates
+func GetDateFormat() string {
+	if format := viper.GetString("date_format"); format != "" {
+		return format
+	}
+
+	return defaultDateFormat
+}
+
+// SetDateFormat saves the Go time layout used to display dates,
+// rejecting layouts that don't contain any date or time element
+func SetDateFormat(format string) error {
+	if err := ValidateDateFormat(format); err != nil {
+		return err
+	}
+
+	if _, err := InitialiseConfigFile(); err != nil {
+		return err
+	}
+
+	viper.Set("date_format", format)
+
+	return viper.WriteConfig()
+}
+
+// ValidateDateFormat checks that the layout formats a fixed time
+// into something other than the layout itself
+func ValidateDateFormat(format string) error {
+	reference := time.Date(2001, time.February, 3, 16, 5, 6, 0, time.UTC)
+
+	if strings.TrimSpace(format) == "" || reference.Format(format) == format {
+		return fmt.Errorf("invalid date format %q, expected a Go time layout such as %q", format, defaultDateFormat)
+	}
+
+	return nil
+}
+
+// GetTheme returns the theme of the rendered notes, empty
+// when it should follow the terminal background
+func GetTheme() string {
+	return viper.GetString("theme")
+}
+
+func SetTheme(theme string) error {
+	if _, err := InitialiseConfigFile(); err != nil {
+		return err
+	}
+
+	viper.Set("theme", theme)
+
+	return viper.WriteConfig()
+}
+
+// GetSortOrder returns the last order the notes list was sorted by
+func GetSortOrder() string {
+	return viper.GetString("sort_order")
+}
+
+func SetSortOrder(order string) error {
+	if _, err := InitialiseConfigFile(); err != nil {
+		return err
+	}
+
+	viper.Set("sort_order", order)
+
+	return viper.WriteConfig()
+}
+
 func InitialiseConfigFile() (string, error) {
 	configPath := viper.ConfigFileUsed()
 
 	if configPath == "" {
-		home, err := os.UserHomeDir()
+		dir, err := GetConfigDir()
 		if err != nil {
 			return "", err
 		}
 
-		dir := filepath.Join(home, notesDir)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return "", err
-		}
-
 		configPath = filepath.Join(dir, ".config.toml")
 		viper.SetConfigFile(configPath)
 
@@ -104,6 +449,21 @@ func InitialiseConfigFile() (string, error) {
 	return configPath, nil
 }
 
+// GetConfigDir returns the directory holding the config and state files
+func GetConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(home, notesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
 func GetConfigFilePath() string {
 	return viper.ConfigFileUsed()
 }
@@ -2,6 +2,7 @@ package keymap
 
 import (
 	"reflect"
+	"slices"
 
 	"github.com/charmbracelet/bubbles/key"
 )
@@ -86,6 +87,61 @@ var ToggleEdit = key.NewBinding(
 	key.WithHelp("E", "toggle edit"),
 )
 
+var Outline = key.NewBinding(
+	key.WithKeys("T"),
+	key.WithHelp("T", "toggle the outline of the note"),
+)
+
+var Links = key.NewBinding(
+	key.WithKeys("L"),
+	key.WithHelp("L", "toggle the links and backlinks of the note"),
+)
+
+var RecentNotes = key.NewBinding(
+	key.WithKeys("R"),
+	key.WithHelp("R", "switch to a recently opened note"),
+)
+
+var CopyPlainText = key.NewBinding(
+	key.WithKeys("C"),
+	key.WithHelp("C", "copy the note as plain text"),
+)
+
+var ToggleSection = key.NewBinding(
+	key.WithKeys("z"),
+	key.WithHelp("z", "collapse or expand a section of the note"),
+)
+
+var ShrinkList = key.NewBinding(
+	key.WithKeys("<"),
+	key.WithHelp("<", "shrink the list pane"),
+)
+
+var GrowList = key.NewBinding(
+	key.WithKeys(">"),
+	key.WithHelp(">", "grow the list pane"),
+)
+
+var VLine = key.NewBinding(
+	key.WithKeys("V"),
+	key.WithHelp("V", "toggle line numbers"),
+)
+
+var RevealWhitespace = key.NewBinding(
+	key.WithKeys("W"),
+	key.WithHelp("W", "toggle the source of the note with its whitespace revealed"),
+)
+
+var ToggleSelect = key.NewBinding(
+	key.WithKeys(" "),
+	key.WithHelp("space", "select note for bulk actions"),
+)
+
+var DeleteSelected = key.NewBinding(
+	key.WithKeys("ctrl+d"),
+	key.WithHelp("ctrl+d", "delete the selected notes"),
+)
+
 var Continue = key.NewBinding(
 	key.WithKeys("alt+enter", "ctrl+s"),
 	key.WithHelp("alt+enter / ctrl+s", "continue"),
@@ -96,6 +152,11 @@ var Save = key.NewBinding(
 	key.WithHelp("enter", "save"),
 )
 
+var ChooseTemplate = key.NewBinding(
+	key.WithKeys("enter"),
+	key.WithHelp("enter", "use template"),
+)
+
 var New = key.NewBinding(
 	key.WithKeys("ctrl+n"),
 	key.WithHelp("ctrl+n", "new note"),
@@ -111,6 +172,66 @@ var Reject = key.NewBinding(
 	key.WithHelp("n", "no"),
 )
 
+var Command = key.NewBinding(
+	key.WithKeys(":"),
+	key.WithHelp(":", "run a command"),
+)
+
+var RunCommand = key.NewBinding(
+	key.WithKeys("enter"),
+	key.WithHelp("enter", "run command"),
+)
+
+var Random = key.NewBinding(
+	key.WithKeys("ctrl+r"),
+	key.WithHelp("ctrl+r", "open a random note"),
+)
+
+var ToggleFolderScope = key.NewBinding(
+	key.WithKeys("ctrl+o"),
+	key.WithHelp("ctrl+o", "toggle between all notes and the current folder"),
+)
+
+var ContentSearch = key.NewBinding(
+	key.WithKeys("ctrl+g"),
+	key.WithHelp("ctrl+g", "search note contents"),
+)
+
+var Duplicate = key.NewBinding(
+	key.WithKeys("ctrl+y"),
+	key.WithHelp("ctrl+y", "duplicate the note"),
+)
+
+var CycleSortOrder = key.NewBinding(
+	key.WithKeys("ctrl+b"),
+	key.WithHelp("ctrl+b", "cycle the order of the notes"),
+)
+
+var Undo = key.NewBinding(
+	key.WithKeys("ctrl+z"),
+	key.WithHelp("ctrl+z", "undo the last delete or rename"),
+)
+
+var Archive = key.NewBinding(
+	key.WithKeys("ctrl+a"),
+	key.WithHelp("ctrl+a", "archive the note"),
+)
+
+var TogglePin = key.NewBinding(
+	key.WithKeys("ctrl+p"),
+	key.WithHelp("ctrl+p", "pin or unpin the note"),
+)
+
+var Scratch = key.NewBinding(
+	key.WithKeys("ctrl+@"),
+	key.WithHelp("ctrl+space", "open the scratch note"),
+)
+
+var AppendTodo = key.NewBinding(
+	key.WithKeys("ctrl+t"),
+	key.WithHelp("ctrl+t", "append a todo to the note"),
+)
+
 var ChangeFocused = key.NewBinding(
 	key.WithKeys("tab"),
 	key.WithHelp("tab", "change focus between editor and list"),
@@ -169,6 +290,8 @@ func CombineKeys(a, b Model) Model {
 }
 
 func ReplaceBinding(bindings []key.Binding, newBinding key.Binding) []key.Binding {
+	bindings = slices.Clone(bindings)
+
 	for i, binding := range bindings {
 		if binding.Help().Key == newBinding.Help().Key {
 			bindings[i] = newBinding
@@ -199,3 +322,87 @@ var ListKeyMap = Model{
 	Down:   Down,
 	Select: FullScreen,
 }
+
+// Context groups the bindings available in one part of the UI
+type Context struct {
+	Name     string
+	Bindings []key.Binding
+}
+
+var ManagerBindings = []key.Binding{
+	Up,
+	Down,
+	Left,
+	Right,
+	FullScreen,
+	ChangeFocused,
+	ShrinkList,
+	GrowList,
+	ToggleEdit,
+	Outline,
+	Links,
+	RecentNotes,
+	ToggleSection,
+	CopyPlainText,
+	VLine,
+	RevealWhitespace,
+	ExternalEditor,
+	ToggleSelect,
+	DeleteSelected,
+	New,
+	Random,
+	ToggleFolderScope,
+	CycleSortOrder,
+	TogglePin,
+	Duplicate,
+	Archive,
+	Undo,
+	AppendTodo,
+	Scratch,
+	Search,
+	ContentSearch,
+	Command,
+	Quit,
+	Help,
+}
+
+var NoteBindings = []key.Binding{
+	Up,
+	Down,
+	Outline,
+	Links,
+	RecentNotes,
+	CopyPlainText,
+	ToggleSection,
+	VLine,
+	RevealWhitespace,
+	ExternalEditor,
+	New,
+	AppendTodo,
+	Quit,
+	Help,
+}
+
+var AddBindings = []key.Binding{
+	ChooseTemplate,
+	ExternalEditor,
+	Continue,
+	Save,
+	Back,
+	QuitForm,
+}
+
+var CommandModeBindings = []key.Binding{
+	RunCommand,
+	Cancel,
+}
+
+// Contexts returns every binding grouped by the part of the UI it applies to
+func Contexts() []Context {
+	return []Context{
+		{Name: "List", Bindings: ManagerBindings},
+		{Name: "Note view", Bindings: NoteBindings},
+		{Name: "Add note", Bindings: AddBindings},
+		{Name: "Command mode", Bindings: CommandModeBindings},
+	}
+}
@@ -0,0 +1,63 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/ionut-t/notes/internal/config"
+)
+
+const stateFile = ".state.json"
+
+// State holds the UI layout remembered between sessions
+type State struct {
+	NoteFocused bool            `json:"note_focused,omitempty"`
+	LineNumbers map[string]bool `json:"line_numbers,omitempty"` // toggled per note name
+	SplitRatio  float64         `json:"split_ratio,omitempty"`  // resized with < and >, 0 to keep split_ratio
+}
+
+func getStatePath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, stateFile), nil
+}
+
+// Load reads the saved state, returning the defaults when none was saved
+func Load() State {
+	var s State
+
+	path, err := getStatePath()
+	if err != nil {
+		return s
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return State{}
+	}
+
+	return s
+}
+
+// Save writes the state so it can be restored in the next session
+func Save(s State) error {
+	path, err := getStatePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
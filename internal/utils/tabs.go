@@ -0,0 +1,31 @@
+package utils
+
+import "strings"
+
+// ExpandTabs replaces the tabs of each line of the text with the spaces
+// up to the next tab stop, placed every width columns
+func ExpandTabs(text string, width int) string {
+	if width < 1 || !strings.Contains(text, "\t") {
+		return text
+	}
+
+	var result strings.Builder
+	column := 0
+
+	for _, r := range text {
+		switch r {
+		case '\t':
+			spaces := width - column%width
+			result.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		case '\n':
+			result.WriteRune(r)
+			column = 0
+		default:
+			result.WriteRune(r)
+			column++
+		}
+	}
+
+	return result.String()
+}
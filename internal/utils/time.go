@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// RelativeTime describes how long before now t was, such as "just now",
+// "5 minutes ago" or "3 days ago". Times in the future count as just now.
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return ago(int(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		return ago(int(d/time.Hour), "hour")
+	}
+
+	days := int(d / (24 * time.Hour))
+
+	switch {
+	case days < 30:
+		return ago(days, "day")
+	case days < 365:
+		return ago(days/30, "month")
+	default:
+		return ago(days/365, "year")
+	}
+}
+
+func ago(n int, unit string) string {
+	return fmt.Sprintf("%d %s ago", n, Ternary(n == 1, unit, unit+"s"))
+}
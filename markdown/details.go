@@ -0,0 +1,145 @@
+package markdown
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ionut-t/notes/internal/utils"
+	"github.com/ionut-t/notes/styles"
+)
+
+var (
+	detailsOpenRegex  = regexp.MustCompile(`(?i)^\s*<details(\s+open)?\s*>\s*(?:<summary>(.*?)</summary>)?\s*$`)
+	detailsCloseRegex = regexp.MustCompile(`(?i)^\s*</details>\s*$`)
+	summaryRegex      = regexp.MustCompile(`(?i)^\s*<summary>(.*?)</summary>\s*$`)
+)
+
+// defaultSummary titles the collapsible sections without a <summary>
+const defaultSummary = "Details"
+
+// Section is a collapsible <details> block of the content
+type Section struct {
+	Summary  string
+	Line     int // Index of the <details> line in Lines
+	Expanded bool
+	Hidden   bool // Whether it's inside a collapsed section
+}
+
+// markDetails finds the <details> blocks, with their <summary> on the same
+// line or the next one. An unclosed block runs to the end of the content.
+func (m *Model) markDetails() {
+	depth := 0
+
+	for i := range m.Lines {
+		line := &m.Lines[i]
+		if line.Type != LineTypeNormal {
+			continue
+		}
+
+		if match := detailsOpenRegex.FindStringSubmatch(line.Content); match != nil {
+			line.Type = LineTypeDetails
+			line.Open = match[1] != ""
+			line.Summary = strings.TrimSpace(match[2])
+
+			if line.Summary == "" && i+1 < len(m.Lines) && m.Lines[i+1].Type == LineTypeNormal {
+				if summary := summaryRegex.FindStringSubmatch(m.Lines[i+1].Content); summary != nil {
+					line.Summary = strings.TrimSpace(summary[1])
+					m.Lines[i+1].Type = LineTypeSummary
+				}
+			}
+
+			line.Summary = utils.Ternary(line.Summary == "", defaultSummary, line.Summary)
+			depth++
+		} else if depth > 0 && detailsCloseRegex.MatchString(line.Content) {
+			line.Type = LineTypeDetailsEnd
+			depth--
+		}
+	}
+}
+
+// Sections returns every collapsible section of the content in order
+func (m Model) Sections() []Section {
+	var sections []Section
+	// the sections enclosing the line, by whether they are expanded
+	var enclosing []bool
+
+	for i, line := range m.Lines {
+		switch line.Type {
+		case LineTypeDetails:
+			expanded := m.isExpanded(i)
+			hidden := slices.Contains(enclosing, false)
+			sections = append(sections, Section{Summary: line.Summary, Line: i, Expanded: expanded, Hidden: hidden})
+			enclosing = append(enclosing, expanded)
+
+		case LineTypeDetailsEnd:
+			enclosing = enclosing[:len(enclosing)-1]
+		}
+	}
+
+	return sections
+}
+
+// ToggleSection collapses the section starting on the given line,
+// or expands it, until the content changes
+func (m *Model) ToggleSection(line int) {
+	if line < 0 || line >= len(m.Lines) || m.Lines[line].Type != LineTypeDetails {
+		return
+	}
+
+	if m.expanded == nil {
+		m.expanded = make(map[int]bool)
+	}
+
+	m.expanded[line] = !m.isExpanded(line)
+}
+
+// isExpanded reports whether the section starting on the line is expanded,
+// which is how <details open> sections start
+func (m Model) isExpanded(line int) bool {
+	if expanded, ok := m.expanded[line]; ok {
+		return expanded
+	}
+
+	return m.Lines[line].Open
+}
+
+// formatSummaryLine renders the summary of a section with a marker
+// showing whether it is expanded
+func (m *Model) formatSummaryLine(line Line, expanded bool) string {
+	marker := utils.Ternary(expanded, "▼ ", "▶ ")
+	return styles.Primary.Bold(true).Render(marker) + styles.Primary.Bold(true).Underline(true).Render(line.Summary)
+}
+
+// markDefinitions finds the definition lists, a term on its own line
+// followed by one or more ": definition" lines
+func (m *Model) markDefinitions() {
+	for i := range m.Lines {
+		line := &m.Lines[i]
+		if line.Type != LineTypeNormal || !strings.HasPrefix(line.Content, ": ") || i == 0 {
+			continue
+		}
+
+		previous := &m.Lines[i-1]
+
+		switch previous.Type {
+		case LineTypeNormal:
+			previous.Type = LineTypeTerm
+		case LineTypeTerm, LineTypeDefinition:
+		default:
+			continue
+		}
+
+		line.Type = LineTypeDefinition
+	}
+}
+
+// formatTermLine renders the term of a definition list
+func (m *Model) formatTermLine(line Line) string {
+	return styles.Text.Bold(true).Render(line.Content)
+}
+
+// formatDefinitionLine renders a definition indented under its term
+func (m *Model) formatDefinitionLine(line Line) string {
+	return "    " + m.applyInlineFormatting(strings.TrimSpace(line.Content[2:]))
+}
@@ -0,0 +1,170 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ionut-t/notes/styles"
+)
+
+// emphasisToken is either plain text or a run of "*" or "_" delimiters.
+// Delimiters left unmatched once the text is processed render literally.
+type emphasisToken struct {
+	text      string
+	delimiter byte
+	canOpen   bool
+	canClose  bool
+	bold      bool
+	italic    bool
+}
+
+// applyEmphasis renders **bold**, *italic* and their combinations such as
+// ***both*** or **a *b* c** in a single pass, the way CommonMark pairs the
+// delimiters: the closest opener of the same kind matches each closer and
+// a pair of runs of at least two delimiters makes bold text.
+func (m *Model) applyEmphasis(text string) string {
+	var result strings.Builder
+
+	for _, t := range matchEmphasis(tokenizeEmphasis(text)) {
+		if t.text == "" {
+			continue
+		}
+
+		if t.bold || t.italic {
+			result.WriteString(styles.Text.Bold(t.bold).Italic(t.italic).Render(t.text))
+		} else {
+			result.WriteString(t.text)
+		}
+	}
+
+	return result.String()
+}
+
+// matchEmphasis pairs the delimiter runs, marking the tokens between
+// each pair as bold or italic and consuming the matched delimiters
+func matchEmphasis(tokens []emphasisToken) []emphasisToken {
+	for closer := 0; closer < len(tokens); {
+		if !tokens[closer].canClose || tokens[closer].text == "" {
+			closer++
+			continue
+		}
+
+		opener := closer - 1
+		for ; opener >= 0; opener-- {
+			t := tokens[opener]
+			if t.delimiter == tokens[closer].delimiter && t.canOpen && t.text != "" {
+				break
+			}
+		}
+
+		if opener < 0 {
+			closer++
+			continue
+		}
+
+		used := 1
+		if len(tokens[opener].text) >= 2 && len(tokens[closer].text) >= 2 {
+			used = 2
+		}
+
+		tokens[opener].text = tokens[opener].text[used:]
+		tokens[closer].text = tokens[closer].text[used:]
+
+		for i := opener + 1; i < closer; i++ {
+			// delimiters inside the pair can't match anything outside it
+			tokens[i].canOpen = false
+			tokens[i].canClose = false
+
+			if used == 2 {
+				tokens[i].bold = true
+			} else {
+				tokens[i].italic = true
+			}
+		}
+	}
+
+	return tokens
+}
+
+// tokenizeEmphasis splits text into delimiter runs and the text between them,
+// keeping inline code spans and ANSI escape sequences as plain text
+func tokenizeEmphasis(text string) []emphasisToken {
+	var tokens []emphasisToken
+	var plain strings.Builder
+
+	flush := func() {
+		if plain.Len() > 0 {
+			tokens = append(tokens, emphasisToken{text: plain.String()})
+			plain.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		switch c := text[i]; c {
+		case 27: // ESC character
+			var scanner ansiScanner
+			end := i
+			for end < len(text) {
+				r, size := utf8.DecodeRuneInString(text[end:])
+				if !scanner.escape(r) {
+					break
+				}
+				end += size
+			}
+
+			plain.WriteString(text[i:end])
+			i = end
+
+		case '`':
+			end := strings.IndexByte(text[i+1:], '`')
+			if end < 0 {
+				plain.WriteByte(c)
+				i++
+				continue
+			}
+
+			plain.WriteString(text[i : i+end+2])
+			i += end + 2
+
+		case '*', '_':
+			end := i
+			for end < len(text) && text[end] == c {
+				end++
+			}
+
+			before, _ := utf8.DecodeLastRuneInString(text[:i])
+			after, _ := utf8.DecodeRuneInString(text[end:])
+
+			canOpen := end < len(text) && !unicode.IsSpace(after)
+			canClose := i > 0 && !unicode.IsSpace(before)
+
+			// underscores inside words, as in snake_case, are not emphasis
+			if c == '_' {
+				canOpen = canOpen && (i == 0 || !isWordRune(before))
+				canClose = canClose && (end == len(text) || !isWordRune(after))
+			}
+
+			flush()
+			tokens = append(tokens, emphasisToken{
+				text:      text[i:end],
+				delimiter: c,
+				canOpen:   canOpen,
+				canClose:  canClose,
+			})
+			i = end
+
+		default:
+			plain.WriteByte(c)
+			i++
+		}
+	}
+
+	flush()
+
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,84 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ionut-t/notes/styles"
+)
+
+var (
+	footnoteDefinitionRegex = regexp.MustCompile(`^\[\^([^\]\s]+)\]:\s*(.*)$`)
+	footnoteReferenceRegex  = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+)
+
+// collectFootnotes numbers the footnote definitions in the order they
+// appear, ignoring repeated definitions of a label
+func (m *Model) collectFootnotes() {
+	m.Footnotes = make(map[string]int)
+
+	for _, line := range m.Lines {
+		if line.Type != LineTypeFootnote {
+			continue
+		}
+
+		if _, ok := m.Footnotes[line.FootnoteID]; !ok {
+			m.Footnotes[line.FootnoteID] = len(m.Footnotes) + 1
+		}
+	}
+}
+
+// applyFootnoteReferences replaces the references to defined footnotes
+// with their number in superscript, leaving undefined ones as they are
+func (m *Model) applyFootnoteReferences(text string) string {
+	return footnoteReferenceRegex.ReplaceAllStringFunc(text, func(match string) string {
+		label := footnoteReferenceRegex.FindStringSubmatch(match)[1]
+
+		number, ok := m.Footnotes[label]
+		if !ok {
+			return match
+		}
+
+		return styles.Info.Render(superscript(number))
+	})
+}
+
+// formatFootnoteLine renders a footnote definition as an item of a numbered list
+func (m *Model) formatFootnoteLine(line Line) string {
+	number := strconv.Itoa(m.Footnotes[line.FootnoteID])
+	return styles.Info.Render(number+".") + " " + m.applyInlineFormatting(line.Content)
+}
+
+// writeFootnotes lists the footnote definitions after the content,
+// each one numbered with the line it is defined on
+func (m *Model) writeFootnotes(result *strings.Builder) {
+	if len(m.Footnotes) == 0 {
+		return
+	}
+
+	result.WriteString("\n")
+
+	listed := make(map[string]bool)
+
+	for i, line := range m.Lines {
+		if line.Type != LineTypeFootnote || listed[line.FootnoteID] {
+			continue
+		}
+
+		listed[line.FootnoteID] = true
+		m.writeLine(result, i+1, m.formatFootnoteLine(line), true)
+	}
+}
+
+var superscriptDigits = []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+
+func superscript(number int) string {
+	var sb strings.Builder
+
+	for _, digit := range strconv.Itoa(number) {
+		sb.WriteRune(superscriptDigits[digit-'0'])
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,38 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LineNumberMode chooses which rendered lines get a line number gutter.
+// Line numbers always refer to the line in the source file.
+type LineNumberMode string
+
+const (
+	LineNumbersOff   LineNumberMode = "off"
+	LineNumbersAll   LineNumberMode = "all"
+	LineNumbersCode  LineNumberMode = "code"
+	LineNumbersProse LineNumberMode = "prose"
+)
+
+func ParseLineNumberMode(value string) (LineNumberMode, error) {
+	switch mode := LineNumberMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return LineNumbersOff, nil
+	case LineNumbersOff, LineNumbersAll, LineNumbersCode, LineNumbersProse:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid line numbers mode %q, expected off, all, code or prose", value)
+	}
+}
+
+// numbersCode reports whether code blocks get line numbers
+func (m *Model) numbersCode() bool {
+	return m.LineNumbers == LineNumbersAll || m.LineNumbers == LineNumbersCode
+}
+
+// numbersProse reports whether everything outside code blocks gets line numbers
+func (m *Model) numbersProse() bool {
+	return m.LineNumbers == LineNumbersAll || m.LineNumbers == LineNumbersProse
+}
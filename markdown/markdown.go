@@ -3,6 +3,8 @@ package markdown
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/alecthomas/chroma"
@@ -12,6 +14,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/ionut-t/notes/internal/utils"
 	"github.com/ionut-t/notes/styles"
+	"github.com/mattn/go-runewidth"
 )
 
 type LineType int
@@ -23,6 +26,17 @@ const (
 	LineTypeCode
 	LineTypeEmpty
 	LineTypeComment
+	LineTypeTable
+	LineTypeTask
+	LineTypeList
+	LineTypeQuote
+	LineTypeRule
+	LineTypeFootnote
+	LineTypeDetails    // Opening <details> tag of a collapsible section
+	LineTypeSummary    // <summary> on the line after the <details> tag
+	LineTypeDetailsEnd // Closing </details> tag
+	LineTypeTerm       // Term of a definition list
+	LineTypeDefinition // ": definition" of the term above
 )
 
 // Line represents a single line in the markdown content with metadata
@@ -31,17 +45,39 @@ type Line struct {
 	Type        LineType
 	HeaderLevel int
 	CodeLang    string
+	Indent      string // Leading whitespace of a task or list item
+	Checked     bool   // Whether a task item is done
+	ListMarker  string // Number of an ordered list item such as "1.", empty for bullets
+	QuoteLevel  int    // Nesting depth of a blockquote, 2 for ">>"
+	FootnoteID  string // Label of a footnote definition, "1" for "[^1]: text"
+	Summary     string // Title of a collapsible section
+	Open        bool   // Whether a collapsible section starts expanded, for <details open>
+	HardBreak   bool   // Whether the line ends with a hard line break: two spaces or a backslash
 }
 
 type Model struct {
-	Content       string
-	Width         int
-	Lines         []Line
-	LineNumbers   bool
-	Style         string // Name of the Chroma style to use
-	ChromaStyle   *chroma.Style
-	DefaultLexer  string // Default lexer to use when language is not specified
-	TerminalTheme string // Terminal theme: "dark" or "light"
+	Content        string
+	Width          int
+	Lines          []Line
+	LineNumbers    LineNumberMode
+	NumberHeaders  bool
+	FirstLine      int            // Line number of the first line of the content, when it doesn't start the file
+	Dictionary     Dictionary     // Words accepted by the spellcheck, nil when it is disabled
+	Footnotes      map[string]int // Number of each defined footnote, in the order they are defined
+	ClickableLinks bool           // Render links as OSC 8 hyperlinks instead of printing their url
+	NoColor        bool           // Leave code blocks unhighlighted, for NO_COLOR and no_color
+	TabWidth       int            // Columns between the tab stops of the code blocks
+	Style          string         // Name of the Chroma style to use
+	ChromaStyle    *chroma.Style
+	CodeThemes     map[string]*chroma.Style // Styles overriding ChromaStyle for the code blocks of a language
+	DefaultLexer   string                   // Default lexer to use when language is not specified
+	TerminalTheme  string                   // Terminal theme: "dark" or "light"
+
+	// rows holds the row of the rendered output each line starts on
+	rows []int
+	// expanded holds the sections toggled since the content changed,
+	// by the line of their <details> tag
+	expanded map[int]bool
 }
 
 // New creates a new markdown model
@@ -51,7 +87,8 @@ func New(content string, width int) Model {
 	m := Model{
 		Content:       content,
 		Width:         width,
-		LineNumbers:   false,
+		LineNumbers:   LineNumbersOff,
+		TabWidth:      4,
 		Style:         "catppuccin-mocha",
 		ChromaStyle:   chStyles.Get("catppuccin-mocha"),
 		DefaultLexer:  "text",
@@ -69,7 +106,48 @@ func New(content string, width int) Model {
 }
 
 func (m *Model) SetLineNumbers(show bool) {
-	m.LineNumbers = show
+	m.LineNumbers = utils.Ternary(show, LineNumbersAll, LineNumbersOff)
+}
+
+// SetLineNumberMode chooses which lines get a line number gutter
+func (m *Model) SetLineNumberMode(mode LineNumberMode) {
+	m.LineNumbers = mode
+}
+
+// SetFirstLine numbers the lines from the given line, for content
+// that starts further down a file such as the body below a frontmatter
+func (m *Model) SetFirstLine(line int) {
+	m.FirstLine = line
+}
+
+// SetNumberHeaders toggles outline numbering (1, 1.1, 1.2, 2...) of the rendered headers
+func (m *Model) SetNumberHeaders(number bool) {
+	m.NumberHeaders = number
+}
+
+// SetNoColor toggles leaving the code blocks unhighlighted
+func (m *Model) SetNoColor(noColor bool) {
+	m.NoColor = noColor
+}
+
+// SetTabWidth sets the columns between the tab stops of the code blocks
+func (m *Model) SetTabWidth(width int) {
+	m.TabWidth = width
+}
+
+// SetClickableLinks toggles rendering links as OSC 8 hyperlinks
+func (m *Model) SetClickableLinks(clickable bool) {
+	m.ClickableLinks = clickable
+}
+
+// SetContent replaces the content and parses it
+func (m *Model) SetContent(content string) {
+	if content != m.Content {
+		m.expanded = nil
+	}
+
+	m.Content = content
+	m.ParseLines()
 }
 
 // ParseLines parses the content into individual lines with metadata
@@ -79,36 +157,49 @@ func (m *Model) ParseLines() {
 
 	inCodeBlock := false
 	var codeLang string
+	// backticks of the opening fence, a fence with fewer is code
+	fenceLength := 0
+	// whether the lines are inside a list, where indented lines
+	// continue the items instead of being code
+	inList := false
 
 	for i, content := range contentLines {
 		line := Line{
 			Content: content,
 		}
 
-		// check if line is a code fence
-		if strings.HasPrefix(content, "```") {
+		fence := codeFenceRegex.FindStringSubmatch(content)
+		// only a bare fence at least as long as the opening one closes a block,
+		// so fenced examples of markdown can contain shorter fences
+		closesFence := inCodeBlock && fence != nil && len(fence[1]) >= fenceLength && strings.TrimSpace(fence[2]) == ""
+
+		if fence != nil && !inCodeBlock {
+			// start of code block
 			line.Type = LineTypeCodeFence
-			if !inCodeBlock {
-				// start of code block
-				inCodeBlock = true
-				codeLang = strings.TrimPrefix(content, "```")
-				line.CodeLang = codeLang
-			} else {
-				// end of code block
-				inCodeBlock = false
-				codeLang = ""
-			}
+			inCodeBlock = true
+			fenceLength = len(fence[1])
+			codeLang = fence[2]
+			line.CodeLang = codeLang
+		} else if closesFence {
+			// end of code block
+			line.Type = LineTypeCodeFence
+			inCodeBlock = false
+			codeLang = ""
 		} else if inCodeBlock {
 			// line is inside a code block
 			line.Type = LineTypeCode
 			line.CodeLang = codeLang
+		} else if code, ok := m.indentedCode(content, i, inList); ok {
+			// indented code block, which can't interrupt a paragraph
+			line.Type = LineTypeCode
+			line.Content = code
 		} else if strings.HasPrefix(content, "#") {
 			level := 0
 			for j, char := range content {
 				if char == '#' {
 					level++
-				} else if char == ' ' && j == level {
-					// proper heading format with space after hash signs
+				} else if char == ' ' && j == level && level <= maxHeaderLevel {
+					// proper heading format with space after at most six hash signs
 					line.Type = LineTypeHeader
 					line.HeaderLevel = level
 					line.Content = strings.TrimSpace(content[j:])
@@ -119,6 +210,31 @@ func (m *Model) ParseLines() {
 					break
 				}
 			}
+		} else if match := footnoteDefinitionRegex.FindStringSubmatch(content); match != nil {
+			// footnote definition: [^label]: text
+			line.Type = LineTypeFootnote
+			line.FootnoteID = match[1]
+			line.Content = match[2]
+		} else if ruleRegex.MatchString(content) {
+			// horizontal rule: ---, *** or ___
+			line.Type = LineTypeRule
+		} else if level, text := parseQuote(content); level > 0 {
+			// blockquote: > text, or >> text when nested
+			line.Type = LineTypeQuote
+			line.QuoteLevel = level
+			line.Content = text
+		} else if match := taskRegex.FindStringSubmatch(content); match != nil {
+			// task list item: - [ ] or - [x]
+			line.Type = LineTypeTask
+			line.Indent = match[1]
+			line.Checked = match[2] != " "
+			line.Content = match[3]
+		} else if match := listRegex.FindStringSubmatch(content); match != nil {
+			// list item: -, * and + bullets or numbers such as 1.
+			line.Type = LineTypeList
+			line.Indent = match[1]
+			line.ListMarker = utils.Ternary(strings.ContainsAny(match[2], "-*+"), "", match[2])
+			line.Content = match[3]
 		} else if len(strings.TrimSpace(content)) == 0 {
 			// line is empty
 			line.Type = LineTypeEmpty
@@ -127,18 +243,95 @@ func (m *Model) ParseLines() {
 			line.Type = LineTypeNormal
 		}
 
+		switch line.Type {
+		case LineTypeNormal, LineTypeList, LineTypeTask, LineTypeQuote:
+			// every line is rendered on its own, so a hard break only drops its marker
+			nextIsText := i+1 < len(contentLines) && strings.TrimSpace(contentLines[i+1]) != ""
+			line.Content, line.HardBreak = cutHardBreak(line.Content, nextIsText)
+		}
+
 		m.Lines[i] = line
+
+		switch {
+		case line.Type == LineTypeList || line.Type == LineTypeTask:
+			inList = true
+		case line.Type != LineTypeEmpty && !strings.HasPrefix(content, " ") && !strings.HasPrefix(content, "\t"):
+			inList = false
+		}
+	}
+
+	m.markTables()
+	m.markDetails()
+	m.markDefinitions()
+	m.collectFootnotes()
+}
+
+// cutHardBreak removes the two or more trailing spaces, or the trailing backslash,
+// that end a line with a hard break. A backslash at the end of a paragraph,
+// with no text after it, is kept as it's written.
+func cutHardBreak(content string, nextIsText bool) (string, bool) {
+	if trimmed := strings.TrimRight(content, " "); trimmed != "" && len(content)-len(trimmed) >= 2 {
+		return trimmed, nextIsText
+	}
+
+	if nextIsText && strings.HasSuffix(content, `\`) && !strings.HasSuffix(content, `\\`) {
+		return strings.TrimSuffix(content, `\`), true
+	}
+
+	return content, false
+}
+
+// maxHeaderLevel is the deepest header level, as in CommonMark
+const maxHeaderLevel = 6
+
+// headerCounter numbers headers as an outline (1, 1.1, 1.2, 2...),
+// treating the shallowest header level used in the content as the top level
+type headerCounter struct {
+	minLevel int
+	counters [maxHeaderLevel]int
+}
+
+func newHeaderCounter(lines []Line) headerCounter {
+	var c headerCounter
+
+	for _, line := range lines {
+		if line.Type == LineTypeHeader && (c.minLevel == 0 || line.HeaderLevel < c.minLevel) {
+			c.minLevel = line.HeaderLevel
+		}
 	}
+
+	return c
+}
+
+// next returns the number of the following header of the given level
+func (c *headerCounter) next(level int) string {
+	depth := min(max(level-c.minLevel, 0), len(c.counters)-1)
+	c.counters[depth]++
+
+	for i := depth + 1; i < len(c.counters); i++ {
+		c.counters[i] = 0
+	}
+
+	parts := make([]string, depth+1)
+	for i := range parts {
+		parts[i] = strconv.Itoa(c.counters[i])
+	}
+
+	return strings.Join(parts, ".")
 }
 
 // formatHeaderLine applies formatting to a header line
-func (m *Model) formatHeaderLine(line Line) string {
+func (m *Model) formatHeaderLine(line Line, number string) string {
 	// style the header content with lipgloss
 	content := line.Content
 
 	// apply inline formatting for header content
 	content = m.applyInlineFormatting(content)
 
+	if number != "" {
+		content = number + " " + content
+	}
+
 	// apply header styling based on level
 	switch line.HeaderLevel {
 	case 1:
@@ -152,57 +345,161 @@ func (m *Model) formatHeaderLine(line Line) string {
 	}
 }
 
+// formatTaskLine replaces the checkbox of a task item with a glyph,
+// striking through the text of the done ones
+func (m *Model) formatTaskLine(line Line) string {
+	content := m.applyInlineFormatting(line.Content)
+
+	if line.Checked {
+		return line.Indent + styles.Success.Render("☑") + " " + lipgloss.NewStyle().Strikethrough(true).Render(content)
+	}
+
+	return line.Indent + styles.Subtext0.Render("☐") + " " + content
+}
+
+// formatListLine replaces the marker of a bullet with a dot,
+// keeping the number of ordered items
+func (m *Model) formatListLine(line Line) string {
+	marker := utils.Ternary(line.ListMarker == "", "•", line.ListMarker)
+	return line.Indent + styles.Accent.Render(marker) + " " + m.applyInlineFormatting(line.Content)
+}
+
+// parseQuote counts the leading > markers of a blockquote line
+// and returns the text after them
+func parseQuote(content string) (int, string) {
+	level := 0
+	text := strings.TrimLeft(content, " ")
+
+	for strings.HasPrefix(text, ">") {
+		level++
+		text = strings.TrimLeft(text[1:], " ")
+	}
+
+	return level, text
+}
+
+// indentedCode returns the line without its indentation when it belongs to
+// an indented code block, which starts after an empty line outside a list
+func (m *Model) indentedCode(content string, i int, inList bool) (string, bool) {
+	match := indentedCodeRegex.FindStringSubmatch(content)
+	if match == nil || inList || strings.TrimSpace(content) == "" {
+		return "", false
+	}
+
+	if i > 0 && m.Lines[i-1].Type != LineTypeEmpty && m.Lines[i-1].Type != LineTypeCode {
+		return "", false
+	}
+
+	return match[1], true
+}
+
+// formatQuoteLine prefixes the quoted text with a bar for each nesting level
+func (m *Model) formatQuoteLine(line Line) string {
+	bar := strings.Repeat(styles.Overlay0.Render("│")+" ", line.QuoteLevel)
+	return bar + styles.Subtext1.Italic(true).Render(m.applyInlineFormatting(line.Content))
+}
+
+// formatRuleLine draws a horizontal rule across the width left by the line numbers
+func (m *Model) formatRuleLine() string {
+	width := m.Width
+	if m.numbersProse() {
+		width -= 5
+	}
+
+	return styles.Overlay0.Render(strings.Repeat("─", max(0, width)))
+}
+
+var (
+	taskRegex         = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\] ?(.*)$`)
+	codeFenceRegex    = regexp.MustCompile("^(`{3,})(.*)$")
+	indentedCodeRegex = regexp.MustCompile(`^(?: {4}|\t)(.*)$`)
+	listRegex         = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	ruleRegex         = regexp.MustCompile(`^ {0,3}(?:(?:- *){3,}|(?:\* *){3,}|(?:_ *){3,})$`)
+	inlineCodeRegex   = regexp.MustCompile("`[^`]+`")
+	imageRegex        = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	urlAutolinkRegex  = regexp.MustCompile(`<((?:https?|ftp)://[^\s<>]+)>`)
+	linkRegex         = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	mailAutolinkRegex = regexp.MustCompile(`<(?:mailto:)?([^\s<>@]+@[^\s<>@]+\.[^\s<>@]+)>`)
+)
+
+// applyAutolinks renders <url> and <email> autolinks, leaving inline code untouched
+func (m *Model) applyAutolinks(text string) string {
+	var result strings.Builder
+
+	last := 0
+	for _, loc := range inlineCodeRegex.FindAllStringIndex(text, -1) {
+		result.WriteString(m.replaceAutolinks(text[last:loc[0]]))
+		result.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+
+	result.WriteString(m.replaceAutolinks(text[last:]))
+
+	return result.String()
+}
+
+func (m *Model) replaceAutolinks(text string) string {
+	text = urlAutolinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		url := urlAutolinkRegex.FindStringSubmatch(match)[1]
+		return m.autolink(url, url)
+	})
+
+	return mailAutolinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		email := mailAutolinkRegex.FindStringSubmatch(match)[1]
+		return m.autolink("mailto:"+email, email)
+	})
+}
+
+func (m *Model) autolink(url, text string) string {
+	text = styles.Info.Underline(true).Render(text)
+	return utils.Ternary(m.ClickableLinks, hyperlink(url, text), text)
+}
+
+// hyperlink wraps text in an OSC 8 escape sequence, which makes it
+// a clickable link in the terminals supporting it
+func hyperlink(url, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
 // applyInlineFormatting applies inline formatting
 func (m *Model) applyInlineFormatting(text string) string {
+	// misspelled words, checked before any markup is rendered
+	text = m.highlightMisspellings(text)
+
+	// footnote references: [^label]
+	text = m.applyFootnoteReferences(text)
+
+	// autolinks: <https://example.com> or <me@example.com>
+	text = m.applyAutolinks(text)
+
+	// links to other notes: [[note]] or [[note|label]]
+	text = m.applyWikiLinks(text)
+
+	// images: ![alt](path), shown as a placeholder before links match them
+	text = imageRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := imageRegex.FindStringSubmatch(match)
+		alt := utils.Ternary(parts[1] == "", "", parts[1]+" ")
+		return styles.Info.Render("🖼 " + alt + "(" + parts[2] + ")")
+	})
+
 	// links: [text](url)
-	linkRegex := regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
 	text = linkRegex.ReplaceAllStringFunc(text, func(match string) string {
 		parts := linkRegex.FindStringSubmatch(match)
 		if len(parts) == 3 {
 			linkText := parts[1]
 			url := parts[2]
-			return styles.Info.Bold(true).Render(linkText) + " " + styles.Info.Render("("+url+")")
-		}
-		return match
-	})
-
-	// bold: **text** or __text__
-	boldRegex1 := regexp.MustCompile(`\*\*(.*?)\*\*`)
-	text = boldRegex1.ReplaceAllStringFunc(text, func(match string) string {
-		parts := boldRegex1.FindStringSubmatch(match)
-		if len(parts) == 2 {
-			return styles.Text.Bold(true).Render(parts[1])
-		}
-		return match
-	})
 
-	boldRegex2 := regexp.MustCompile(`__(.*?)__`)
-	text = boldRegex2.ReplaceAllStringFunc(text, func(match string) string {
-		parts := boldRegex2.FindStringSubmatch(match)
-		if len(parts) == 2 {
-			return styles.Text.Bold(true).Render(parts[1])
-		}
-		return match
-	})
+			if m.ClickableLinks {
+				return hyperlink(url, styles.Info.Bold(true).Render(linkText))
+			}
 
-	// italic: *text* or _text_
-	italicRegex1 := regexp.MustCompile(`\*(.*?)\*`)
-	text = italicRegex1.ReplaceAllStringFunc(text, func(match string) string {
-		parts := italicRegex1.FindStringSubmatch(match)
-		if len(parts) == 2 {
-			return styles.Text.Italic(true).Render(parts[1])
+			return styles.Info.Bold(true).Render(linkText) + " " + styles.Info.Render("("+url+")")
 		}
 		return match
 	})
 
-	italicRegex2 := regexp.MustCompile(`_(.*?)_`)
-	text = italicRegex2.ReplaceAllStringFunc(text, func(match string) string {
-		parts := italicRegex2.FindStringSubmatch(match)
-		if len(parts) == 2 {
-			return styles.Text.Italic(true).Render(parts[1])
-		}
-		return match
-	})
+	// bold and italic: **text**, __text__, *text*, _text_ and their combinations
+	text = m.applyEmphasis(text)
 
 	// inline code: `code`
 	codeRegex := regexp.MustCompile("`([^`]+)`")
@@ -223,6 +520,10 @@ func (m *Model) syntaxHighlightWithChroma(code, language string) string {
 		return ""
 	}
 
+	if m.NoColor {
+		return code
+	}
+
 	normalizedLang := strings.ToLower(strings.TrimSpace(language))
 
 	lexer := lexers.Get(normalizedLang)
@@ -251,6 +552,10 @@ func (m *Model) syntaxHighlightWithChroma(code, language string) string {
 		}
 	}
 
+	if override, ok := m.codeTheme(normalizedLang, lexer); ok {
+		style = override
+	}
+
 	// create a buffer to hold the highlighted code
 	var buf strings.Builder
 
@@ -277,10 +582,11 @@ func (m *Model) highlightCodeBlock(block []Line) []string {
 	// extract language from the first line
 	language := block[0].CodeLang
 
-	// combine all lines of code
+	// combine all lines of code, with the tabs expanded so the
+	// indentation doesn't depend on the tab stops of the terminal
 	var codeBuilder strings.Builder
 	for _, line := range block {
-		codeBuilder.WriteString(line.Content)
+		codeBuilder.WriteString(utils.ExpandTabs(line.Content, m.TabWidth))
 		codeBuilder.WriteString("\n")
 	}
 	code := codeBuilder.String()
@@ -302,41 +608,109 @@ func (m *Model) highlightCodeBlock(block []Line) []string {
 }
 
 // addLineNumber adds line number to the beginning of a line
-func (m *Model) addLineNumber(lineNum int, line string) string {
-	if !m.LineNumbers {
+func (m *Model) addLineNumber(lineNum int, line string, code bool) string {
+	if !utils.Ternary(code, m.numbersCode(), m.numbersProse()) {
 		return line
 	}
 
 	// format line number with right alignment and padding
-	lineNumStr := fmt.Sprintf("%3d ", lineNum)
+	lineNumStr := fmt.Sprintf("%3d ", lineNum+max(m.FirstLine, 1)-1)
 	return styles.Subtext0.Render(lineNumStr) + line
 }
 
+// ansiScanner tracks whether the runes of a styled text belong to an escape
+// sequence: SGR styling such as ESC [ 1 m, or OSC 8 hyperlinks, which are
+// ESC ] 8 ; ; url ESC \ and may also end with BEL
+type ansiScanner struct {
+	state int
+}
+
+const (
+	ansiText = iota
+	ansiEscape
+	ansiCSI
+	ansiOSC
+	ansiOSCEscape
+)
+
+// escape reports whether r is part of an escape sequence
+func (s *ansiScanner) escape(r rune) bool {
+	switch s.state {
+	case ansiEscape:
+		switch r {
+		case '[':
+			s.state = ansiCSI
+		case ']':
+			s.state = ansiOSC
+		default:
+			s.state = ansiText
+		}
+	case ansiCSI:
+		if r == 'm' {
+			s.state = ansiText
+		}
+	case ansiOSC:
+		if r == 7 { // BEL
+			s.state = ansiText
+		} else if r == 27 {
+			s.state = ansiOSCEscape
+		}
+	case ansiOSCEscape:
+		s.state = ansiText
+	default:
+		if r != 27 { // ESC character
+			return false
+		}
+
+		s.state = ansiEscape
+	}
+
+	return true
+}
+
 // estimateVisibleLength estimates the visible length of text with lipgloss styling
 func (m *Model) estimateVisibleLength(text string) int {
-	// count visible runes and ignore ANSI escape sequences
-	// Lipgloss uses ANSI escape sequences which start with ESC (27) and '['
-	// and end with 'm'
-	visible := 0
-	inEscapeSeq := false
+	// measure the visible text and ignore ANSI escape sequences
+	var visible strings.Builder
+	var scanner ansiScanner
 
 	for _, r := range text {
-		if inEscapeSeq {
-			if r == 'm' {
-				inEscapeSeq = false
-			}
-			continue
+		if !scanner.escape(r) {
+			visible.WriteRune(r)
 		}
+	}
 
-		if r == 27 { // ESC character
-			inEscapeSeq = true
+	// wide characters such as CJK and most emoji take two cells,
+	// zero-width joiners and combining marks none
+	return runewidth.StringWidth(visible.String())
+}
+
+// splitByWidth breaks a word too wide for a line into chunks of at most
+// width cells, keeping the ANSI escape sequences with the text they style
+func (m *Model) splitByWidth(word string, width int) []string {
+	var chunks []string
+	var current strings.Builder
+	currentWidth := 0
+	var scanner ansiScanner
+
+	for _, r := range word {
+		if scanner.escape(r) {
+			current.WriteRune(r)
 			continue
 		}
 
-		visible++
+		runeWidth := runewidth.RuneWidth(r)
+		if currentWidth > 0 && currentWidth+runeWidth > width {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentWidth = 0
+		}
+
+		current.WriteRune(r)
+		currentWidth += runeWidth
 	}
 
-	return visible
+	return append(chunks, current.String())
 }
 
 // wrapLine wraps a line to fit within the specified width
@@ -355,6 +729,21 @@ func (m *Model) wrapLine(line string, width int) []string {
 	for _, word := range words {
 		wordVisibleLength := m.estimateVisibleLength(word)
 
+		// words wider than a line, such as CJK text without spaces,
+		// are broken across lines
+		if wordVisibleLength > width {
+			if currentLineVisibleLength > 0 {
+				wrappedLines = append(wrappedLines, currentLine)
+			}
+
+			chunks := m.splitByWidth(word, width)
+			wrappedLines = append(wrappedLines, chunks[:len(chunks)-1]...)
+
+			currentLine = chunks[len(chunks)-1]
+			currentLineVisibleLength = m.estimateVisibleLength(currentLine)
+			continue
+		}
+
 		// if adding this word would exceed width, start a new line
 		if currentLineVisibleLength > 0 &&
 			currentLineVisibleLength+1+wordVisibleLength > width {
@@ -387,16 +776,53 @@ func (m *Model) Render() string {
 	var codeBlock []Line
 	inCodeBlock := false
 
+	headers := newHeaderCounter(m.Lines)
+	tables := m.renderTables(utils.Ternary(m.numbersProse(), m.Width-4, m.Width))
+
 	var renderCodeBlockFence = func(lineNum int, line Line) {
 		codeLang := utils.Ternary(line.CodeLang == "", "", " "+line.CodeLang)
 		lineWidth := m.Width - lipgloss.Width(codeLang) - 6
-		lineWithNum := m.addLineNumber(lineNum, styles.Error.Render(strings.Repeat("─", lineWidth)+codeLang))
+		// the language labels the opening fence, dimmed next to the rule
+		label := styles.Subtext0.Faint(true).Render(codeLang)
+		lineWithNum := m.addLineNumber(lineNum, styles.Error.Render(strings.Repeat("─", lineWidth))+label, true)
 		result.WriteString(lineWithNum + "\n")
 	}
 
+	m.rows = make([]int, len(m.Lines))
+	rows, counted := 0, 0
+
+	// depth is the nesting of the collapsible sections, collapsed the depth
+	// of the outermost collapsed one, whose lines are left out
+	depth, collapsed := 0, 0
+
 	for i, line := range m.Lines {
 		lineNum := i + 1
 
+		rows += strings.Count(result.String()[counted:], "\n")
+		counted = result.Len()
+		m.rows[i] = rows
+
+		switch {
+		case line.Type == LineTypeDetails:
+			depth++
+			if collapsed == 0 {
+				expanded := m.isExpanded(i)
+				m.writeLine(&result, lineNum, m.formatSummaryLine(line, expanded), true)
+				collapsed = utils.Ternary(expanded, 0, depth)
+			}
+			continue
+
+		case line.Type == LineTypeDetailsEnd:
+			if collapsed == depth {
+				collapsed = 0
+			}
+			depth--
+			continue
+
+		case line.Type == LineTypeSummary || collapsed > 0:
+			continue
+		}
+
 		if line.Type == LineTypeCodeFence {
 			if !inCodeBlock {
 				// start of code block
@@ -410,7 +836,7 @@ func (m *Model) Render() string {
 				for j, hLine := range highlightedLines {
 					if j < len(codeBlock) {
 						codeLineNum := i - len(codeBlock) + j + 1
-						lineWithNum := m.addLineNumber(codeLineNum, "  "+hLine)
+						lineWithNum := m.addLineNumber(codeLineNum, "  "+hLine, true)
 						result.WriteString(lineWithNum + "\n")
 					}
 				}
@@ -434,7 +860,11 @@ func (m *Model) Render() string {
 
 		switch line.Type {
 		case LineTypeHeader:
-			formattedLine = m.formatHeaderLine(line)
+			var number string
+			if m.NumberHeaders {
+				number = headers.next(line.HeaderLevel)
+			}
+			formattedLine = m.formatHeaderLine(line, number)
 
 		case LineTypeEmpty:
 			formattedLine = ""
@@ -442,46 +872,157 @@ func (m *Model) Render() string {
 		case LineTypeComment:
 			formattedLine = styles.Subtext0.Faint(true).Render(line.Content)
 
+		case LineTypeTable:
+			formattedLine = tables[i]
+
+		case LineTypeTask:
+			formattedLine = m.formatTaskLine(line)
+
+		case LineTypeList:
+			formattedLine = m.formatListLine(line)
+
+		case LineTypeQuote:
+			formattedLine = m.formatQuoteLine(line)
+
+		case LineTypeRule:
+			formattedLine = m.formatRuleLine()
+
+		case LineTypeFootnote:
+			// definitions are listed at the end by writeFootnotes
+			continue
+
+		case LineTypeCode:
+			// indented code, as fenced code is highlighted with its block
+			result.WriteString(m.addLineNumber(lineNum, "  "+m.highlightCodeBlock([]Line{line})[0], true) + "\n")
+			continue
+
+		case LineTypeTerm:
+			formattedLine = m.formatTermLine(line)
+
+		case LineTypeDefinition:
+			formattedLine = m.formatDefinitionLine(line)
+
 		default:
 			formattedLine = m.applyInlineFormatting(line.Content)
 		}
 
-		// for normal text (not code or comments), wrap the line if it's too long
-		if line.Type != LineTypeCode && line.Type != LineTypeComment && len(formattedLine) > 0 {
-			// Calculate available width accounting for line numbers
-			availableWidth := m.Width
-			if m.LineNumbers {
-				availableWidth -= 5
-			}
+		// for normal text (not code, comments or tables), wrap the line if it's too long
+		wrap := line.Type != LineTypeCode && line.Type != LineTypeComment && line.Type != LineTypeTable
+		m.writeLine(&result, lineNum, formattedLine, wrap)
+	}
 
-			visibleLength := m.estimateVisibleLength(formattedLine)
-			if visibleLength > availableWidth {
-				wrappedLines := m.wrapLine(formattedLine, availableWidth)
+	m.writeFootnotes(&result)
 
-				// add the first line with line number
-				lineWithNum := m.addLineNumber(lineNum, wrappedLines[0])
-				result.WriteString(lineWithNum + "\n")
+	return result.String()
+}
 
-				// add continuation lines with no line number
-				for j := 1; j < len(wrappedLines); j++ {
-					if m.LineNumbers {
-						// create a continuation indicator with subtle styling
-						continuationPrefix := styles.Subtext0.Render("    ")
-						result.WriteString(continuationPrefix + wrappedLines[j] + "\n")
-					} else {
-						result.WriteString(wrappedLines[j] + "\n")
-					}
-				}
+// writeLine writes a rendered line with its number, wrapping it
+// onto continuation lines without numbers when it's too long
+func (m *Model) writeLine(result *strings.Builder, lineNum int, formattedLine string, wrap bool) {
+	if wrap && len(formattedLine) > 0 {
+		// calculate available width accounting for line numbers
+		availableWidth := m.Width
+		if m.numbersProse() {
+			availableWidth -= 5
+		}
 
-				continue // skip the normal line addition below
+		visibleLength := m.estimateVisibleLength(formattedLine)
+		if visibleLength > availableWidth {
+			wrappedLines := m.wrapLine(formattedLine, availableWidth)
+
+			// add the first line with line number
+			lineWithNum := m.addLineNumber(lineNum, wrappedLines[0], false)
+			result.WriteString(lineWithNum + "\n")
+
+			// add continuation lines with no line number
+			for j := 1; j < len(wrappedLines); j++ {
+				if m.numbersProse() {
+					// create a continuation indicator with subtle styling
+					continuationPrefix := styles.Subtext0.Render("    ")
+					result.WriteString(continuationPrefix + wrappedLines[j] + "\n")
+				} else {
+					result.WriteString(wrappedLines[j] + "\n")
+				}
 			}
+
+			return
 		}
+	}
 
-		lineWithNum := m.addLineNumber(lineNum, formattedLine)
-		result.WriteString(lineWithNum + "\n")
+	lineWithNum := m.addLineNumber(lineNum, formattedLine, false)
+	result.WriteString(lineWithNum + "\n")
+}
+
+// RenderedRow returns the row of the last rendered output the line starts on.
+// Lines inside code blocks are rendered with their closing fence, so only
+// the rows of the other lines, such as headers, are exact.
+func (m Model) RenderedRow(line int) int {
+	if line < 0 || line >= len(m.rows) {
+		return 0
 	}
 
-	return result.String()
+	return m.rows[line]
+}
+
+// SourceLine returns the line of the content rendered on the given row
+func (m Model) SourceLine(row int) int {
+	line, _ := slices.BinarySearch(m.rows, row+1)
+	return max(line-1, 0)
+}
+
+// Heading is a header of the content, as listed in an outline
+type Heading struct {
+	Level int
+	Text  string
+	Line  int // Index of the header in Lines
+}
+
+// Headings returns every header of the content in order
+func (m Model) Headings() []Heading {
+	var headings []Heading
+
+	for i, line := range m.Lines {
+		if line.Type == LineTypeHeader {
+			headings = append(headings, Heading{Level: line.HeaderLevel, Text: line.Content, Line: i})
+		}
+	}
+
+	return headings
+}
+
+// CodeBlock is a fenced code block of the content
+type CodeBlock struct {
+	Language string
+	Code     string
+	Line     int // Index of the opening fence in Lines
+}
+
+// CodeBlocks returns every closed code block of the content in order
+func (m Model) CodeBlocks() []CodeBlock {
+	var blocks []CodeBlock
+	var code []string
+	start := -1
+
+	for i, line := range m.Lines {
+		switch {
+		case line.Type == LineTypeCodeFence && start < 0:
+			start = i
+			code = nil
+
+		case line.Type == LineTypeCodeFence:
+			blocks = append(blocks, CodeBlock{
+				Language: strings.TrimSpace(m.Lines[start].CodeLang),
+				Code:     strings.Join(code, "\n"),
+				Line:     start,
+			})
+			start = -1
+
+		case start >= 0:
+			code = append(code, line.Content)
+		}
+	}
+
+	return blocks
 }
 
 // RenderPreservingAll renders the markdown content preserving every line
@@ -502,7 +1043,7 @@ func (m *Model) RenderPreservingAll() string {
 				inCodeBlock = true
 				codeBlock = []Line{}
 				// output the code fence marker
-				formattedLine := m.addLineNumber(lineNum, styles.Subtext1.Render(line.Content))
+				formattedLine := m.addLineNumber(lineNum, styles.Subtext1.Render(line.Content), true)
 				result.WriteString(formattedLine + "\n")
 			} else {
 				// end of code block - highlight and add to result
@@ -510,13 +1051,13 @@ func (m *Model) RenderPreservingAll() string {
 
 				for j, hLine := range highlightedLines {
 					if j < len(codeBlock) {
-						codeLineNum := i - len(codeBlock) + j
-						lineWithNum := m.addLineNumber(codeLineNum, "  "+hLine)
+						codeLineNum := i - len(codeBlock) + j + 1
+						lineWithNum := m.addLineNumber(codeLineNum, "  "+hLine, true)
 						result.WriteString(lineWithNum + "\n")
 					}
 				}
 
-				formattedLine := m.addLineNumber(lineNum, styles.Subtext1.Render(line.Content))
+				formattedLine := m.addLineNumber(lineNum, styles.Subtext1.Render(line.Content), true)
 				result.WriteString(formattedLine + "\n")
 
 				inCodeBlock = false
@@ -534,7 +1075,7 @@ func (m *Model) RenderPreservingAll() string {
 
 		switch line.Type {
 		case LineTypeHeader:
-			formattedLine = m.formatHeaderLine(line)
+			formattedLine = m.formatHeaderLine(line, "")
 
 		case LineTypeEmpty:
 			formattedLine = ""
@@ -542,6 +1083,27 @@ func (m *Model) RenderPreservingAll() string {
 		case LineTypeComment:
 			formattedLine = styles.Subtext0.Faint(true).Render(line.Content)
 
+		case LineTypeTask:
+			formattedLine = m.formatTaskLine(line)
+
+		case LineTypeList:
+			formattedLine = m.formatListLine(line)
+
+		case LineTypeQuote:
+			formattedLine = m.formatQuoteLine(line)
+
+		case LineTypeRule:
+			formattedLine = m.formatRuleLine()
+
+		case LineTypeFootnote:
+			// definitions are listed at the end by writeFootnotes
+			continue
+
+		case LineTypeCode:
+			// indented code, as fenced code is highlighted with its block
+			result.WriteString(m.addLineNumber(lineNum, "  "+m.highlightCodeBlock([]Line{line})[0], true) + "\n")
+			continue
+
 		default:
 			formattedLine = m.applyInlineFormatting(line.Content)
 		}
@@ -550,7 +1112,7 @@ func (m *Model) RenderPreservingAll() string {
 		if line.Type != LineTypeComment && len(formattedLine) > 0 {
 			// calculate available width accounting for line numbers
 			availableWidth := m.Width
-			if m.LineNumbers {
+			if m.numbersProse() {
 				availableWidth -= 5
 			}
 
@@ -559,12 +1121,12 @@ func (m *Model) RenderPreservingAll() string {
 				wrappedLines := m.wrapLine(formattedLine, availableWidth)
 
 				// add the first line with line number
-				lineWithNum := m.addLineNumber(lineNum, wrappedLines[0])
+				lineWithNum := m.addLineNumber(lineNum, wrappedLines[0], false)
 				result.WriteString(lineWithNum + "\n")
 
 				// add continuation lines with indentation
 				for j := 1; j < len(wrappedLines); j++ {
-					if m.LineNumbers {
+					if m.numbersProse() {
 						continuationPrefix := styles.Subtext0.Render("    ")
 						result.WriteString(continuationPrefix + wrappedLines[j] + "\n")
 					} else {
@@ -576,7 +1138,7 @@ func (m *Model) RenderPreservingAll() string {
 			}
 		}
 
-		lineWithNum := m.addLineNumber(lineNum, formattedLine)
+		lineWithNum := m.addLineNumber(lineNum, formattedLine, false)
 		result.WriteString(lineWithNum + "\n")
 	}
 
@@ -0,0 +1,806 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyInlineFormatting_Autolinks(t *testing.T) {
+	t.Parallel()
+
+	m := New("", 80)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "url",
+			input:    "<https://example.com>",
+			expected: "https://example.com",
+		},
+		{
+			name:     "email",
+			input:    "write to <me@example.com>",
+			expected: "write to me@example.com",
+		},
+		{
+			name:     "mailto email",
+			input:    "<mailto:me@example.com>",
+			expected: "me@example.com",
+		},
+		{
+			name:     "url next to punctuation",
+			input:    "see (<https://example.com/docs>).",
+			expected: "see (https://example.com/docs).",
+		},
+		{
+			name:     "inside inline code",
+			input:    "`<https://example.com>`",
+			expected: "<https://example.com>",
+		},
+		{
+			name:     "html comment",
+			input:    "<!-- note -->",
+			expected: "<!-- note -->",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, m.applyInlineFormatting(tt.input))
+		})
+	}
+}
+
+func TestRender_NumberHeaders(t *testing.T) {
+	t.Parallel()
+
+	content := "# Intro\n## Scope\n## Goals\n### Detail\n# Design\n## Parser"
+
+	m := New(content, 80)
+	m.SetNumberHeaders(true)
+
+	assert.Equal(t, "1 Intro\n1.1 Scope\n1.2 Goals\n1.2.1 Detail\n2 Design\n2.1 Parser\n", m.Render())
+
+	m.SetNumberHeaders(false)
+	assert.Equal(t, "Intro\nScope\nGoals\nDetail\nDesign\nParser\n", m.Render())
+	assert.Equal(t, content, m.Content, "Numbering should not modify the content")
+}
+
+func TestRender_NumberHeaders_StartsFromShallowestLevel(t *testing.T) {
+	t.Parallel()
+
+	m := New("## First\n### Child\n## Second", 80)
+	m.SetNumberHeaders(true)
+
+	assert.Equal(t, "1 First\n1.1 Child\n2 Second\n", m.Render())
+}
+
+func TestRender_NumberHeaders_TooDeep(t *testing.T) {
+	t.Parallel()
+
+	m := New("# A\n####### B\n###### C", 80)
+	m.SetNumberHeaders(true)
+
+	assert.Equal(t, LineTypeComment, m.Lines[1].Type, "Seven hash signs should not make a header")
+	assert.Equal(t, "1 A\n####### B\n1.0.0.0.0.1 C\n", m.Render())
+}
+
+func TestRender_LineNumberModes(t *testing.T) {
+	t.Parallel()
+
+	content := "Intro\n```\ncode\n```\nOutro"
+
+	tests := []struct {
+		mode LineNumberMode
+		want []string
+	}{
+		{mode: LineNumbersOff, want: []string{"Intro", "  code", "Outro"}},
+		{mode: LineNumbersAll, want: []string{"  1 Intro", "  3   code", "  5 Outro"}},
+		{mode: LineNumbersCode, want: []string{"Intro", "  3   code", "Outro"}},
+		{mode: LineNumbersProse, want: []string{"  1 Intro", "  code", "  5 Outro"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			t.Parallel()
+
+			m := New(content, 40)
+			m.SetLineNumberMode(tt.mode)
+
+			lines := strings.Split(m.Render(), "\n")
+
+			for _, want := range tt.want {
+				assert.Contains(t, lines, want)
+			}
+		})
+	}
+}
+
+func TestRender_LineNumbersFirstLine(t *testing.T) {
+	t.Parallel()
+
+	m := New("Intro\n```\ncode\n```", 40)
+	m.SetLineNumberMode(LineNumbersAll)
+	m.SetFirstLine(5)
+
+	lines := strings.Split(m.Render(), "\n")
+
+	assert.Contains(t, lines, "  5 Intro")
+	assert.Contains(t, lines, "  7   code")
+}
+
+func TestParseLineNumberMode(t *testing.T) {
+	t.Parallel()
+
+	mode, err := ParseLineNumberMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, LineNumbersOff, mode)
+
+	mode, err = ParseLineNumberMode("Code")
+	assert.NoError(t, err)
+	assert.Equal(t, LineNumbersCode, mode)
+
+	_, err = ParseLineNumberMode("some")
+	assert.Error(t, err)
+}
+
+func TestMisspellings(t *testing.T) {
+	t.Parallel()
+
+	m := New("", 80)
+
+	assert.Empty(t, m.misspellings("Tihs is wrnog"), "Spellcheck should be off without a dictionary")
+
+	m.SetDictionary(Dictionary{"this": {}, "is": {}, "a": {}, "note": {}, "link": {}, "see": {}})
+
+	text := "Tihs is a note's `codez` see [lnik](https://exmaple.com) <https://exmaple.com> wrnog"
+
+	var words []string
+	for _, r := range m.misspellings(text) {
+		words = append(words, text[r[0]:r[1]])
+	}
+
+	assert.Equal(t, []string{"Tihs", "wrnog"}, words)
+}
+
+func TestMisspellings_Content(t *testing.T) {
+	t.Parallel()
+
+	m := New("", 80)
+	m.SetDictionary(Dictionary{"this": {}, "is": {}, "code": {}, "fine": {}})
+
+	content := "This is fien\n\n```go\nfmt.Println(\"wrnog\")\n```\n\n    indnted code\n\n# Titel\n- Thsi is `codez`"
+
+	assert.Equal(t, []Range{
+		{Line: 0, Start: 8, End: 12},
+		{Line: 8, Start: 2, End: 7},
+		{Line: 9, Start: 2, End: 6},
+	}, m.Misspellings(content))
+}
+
+func TestLoadDictionary(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "words")
+	assert.NoError(t, os.WriteFile(path, []byte("Hello\nworld\n"), 0644))
+
+	dictionary, err := LoadDictionary(path, []string{"Kubernetes"})
+	assert.NoError(t, err)
+
+	assert.True(t, dictionary.Contains("hello"))
+	assert.True(t, dictionary.Contains("World"))
+	assert.True(t, dictionary.Contains("kubernetes"))
+	assert.False(t, dictionary.Contains("helo"))
+}
+
+func TestRender_Tables(t *testing.T) {
+	t.Parallel()
+
+	content := "| Name | Qty | Note |\n|:-----|:---:|-----:|\n| apple | 3 | fresh |\n| kiwi | 12 | x |"
+
+	m := New(content, 80)
+
+	expected := "" +
+		"Name  │ Qty │  Note\n" +
+		"──────┼─────┼──────\n" +
+		"apple │  3  │ fresh\n" +
+		"kiwi  │ 12  │     x\n"
+
+	assert.Equal(t, expected, m.Render())
+}
+
+func TestRender_TablesInlineFormatting(t *testing.T) {
+	t.Parallel()
+
+	content := "| Name | Link |\n|------|------|\n| **bold** name | [docs](https://example.com/docs) |\n| `code` | x |"
+
+	m := New(content, 80)
+	m.SetClickableLinks(true)
+
+	lines := strings.Split(strings.TrimSuffix(m.Render(), "\n"), "\n")
+	assert.Len(t, lines, 4)
+
+	// the escape sequences of the styled cells don't count towards the column widths
+	for _, line := range lines {
+		assert.Equal(t, m.estimateVisibleLength(lines[0]), m.estimateVisibleLength(line))
+	}
+
+	assert.NotContains(t, lines[2], "**")
+	assert.Contains(t, lines[2], "bold name │ \x1b]8;;https://example.com/docs\x1b\\docs")
+	assert.Equal(t, "code      │ x   ", lines[3])
+}
+
+func TestRender_ListItemsInlineFormatting(t *testing.T) {
+	t.Parallel()
+
+	m := New("- **bold** and `code`\n1. [docs](https://example.com)", 80)
+
+	assert.Equal(t, "• bold and code\n1. docs (https://example.com)\n", m.Render())
+}
+
+func TestRender_CodeBlockTabs(t *testing.T) {
+	t.Parallel()
+
+	m := New("```\nif x {\n\treturn\n}\n```\n\n\tindented", 80)
+	m.SetNoColor(true)
+
+	rendered := m.Render()
+	assert.Contains(t, rendered, "  if x {\n      return\n  }\n")
+	assert.Contains(t, rendered, "\n  indented\n")
+
+	m.SetTabWidth(2)
+	assert.Contains(t, m.Render(), "\n    return\n")
+}
+
+func TestRevealWhitespace(t *testing.T) {
+	t.Parallel()
+
+	content := "# Title\nline break  \n\tcode\ta\n- item\t"
+
+	assert.Equal(t, "#·Title\nline·break··\n→   code→   a\n-·item→ ", RevealWhitespace(content, 4))
+	assert.Equal(t, "ab→ c", RevealWhitespace("ab\tc", 4), "Tabs should be padded to the next tab stop")
+}
+
+func TestParseLines_HardBreaks(t *testing.T) {
+	t.Parallel()
+
+	content := "one  \ntwo\\\n- item   \nescaped\\\\\nlast\\\n\nthree four five six seven eight  \nnext"
+
+	m := New(content, 20)
+
+	assert.True(t, m.Lines[0].HardBreak)
+	assert.Equal(t, "one", m.Lines[0].Content)
+	assert.True(t, m.Lines[1].HardBreak)
+	assert.Equal(t, "two", m.Lines[1].Content)
+	assert.True(t, m.Lines[2].HardBreak)
+	assert.Equal(t, "item", m.Lines[2].Content)
+	assert.False(t, m.Lines[3].HardBreak, "An escaped backslash is not a hard break")
+	assert.False(t, m.Lines[4].HardBreak, "A backslash ending a paragraph is kept")
+
+	assert.Equal(t, "one\ntwo\n• item\nescaped\\\\\nlast\\\n\nthree four five six\nseven eight\nnext\n", m.Render())
+}
+
+func TestRender_TablesFitWidth(t *testing.T) {
+	t.Parallel()
+
+	content := "| a | b |\n|---|---|\n| a very long cell that does not fit | ok |"
+
+	m := New(content, 20)
+
+	for _, line := range strings.Split(strings.TrimSuffix(m.Render(), "\n"), "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 20)
+	}
+}
+
+func TestRender_TablesInCodeBlocksUntouched(t *testing.T) {
+	t.Parallel()
+
+	m := New("```\n| a | b |\n|---|---|\n```", 80)
+
+	assert.Contains(t, m.Render(), "  | a | b |\n  |---|---|\n")
+}
+
+func TestRender_NoColorLeavesCodeUnhighlighted(t *testing.T) {
+	t.Parallel()
+
+	m := New("```go\nfunc main() {}\n```", 80)
+	assert.Contains(t, m.Render(), "\x1b[")
+
+	m.SetNoColor(true)
+	rendered := m.Render()
+	assert.Contains(t, rendered, "\n  func main() {}\n")
+	assert.NotContains(t, rendered, "\x1b[")
+}
+
+func TestRender_TaskItems(t *testing.T) {
+	t.Parallel()
+
+	m := New("- [ ] buy milk\n  - [x] nested done\n* [X] star bullet\n- [not a task]", 80)
+
+	assert.Equal(t, LineTypeTask, m.Lines[0].Type)
+	assert.False(t, m.Lines[0].Checked)
+	assert.True(t, m.Lines[1].Checked)
+	assert.Equal(t, "  ", m.Lines[1].Indent)
+	assert.Equal(t, LineTypeList, m.Lines[3].Type)
+
+	assert.Equal(t, "☐ buy milk\n  ☑ nested done\n☑ star bullet\n• [not a task]\n", m.Render())
+}
+
+func TestSetTheme(t *testing.T) {
+	t.Parallel()
+
+	m := New("", 80)
+
+	assert.NoError(t, m.SetTheme("light"))
+	assert.Equal(t, "catppuccin-latte", m.Style)
+	assert.Equal(t, "light", m.TerminalTheme)
+
+	assert.NoError(t, m.SetTheme("monokai"))
+	assert.Equal(t, "monokai", m.Style)
+
+	assert.Error(t, m.SetTheme("no-such-theme"))
+	assert.Equal(t, "monokai", m.Style, "Invalid themes should keep the current one")
+}
+
+func TestApplyEmphasis(t *testing.T) {
+	t.Parallel()
+
+	type span struct {
+		text         string
+		bold, italic bool
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []span
+	}{
+		{
+			name:     "bold and italic",
+			input:    "***x***",
+			expected: []span{{"x", true, true}},
+		},
+		{
+			name:     "italic inside bold",
+			input:    "**a *b* c**",
+			expected: []span{{"a ", true, false}, {"b", true, true}, {" c", true, false}},
+		},
+		{
+			name:     "mixed markers",
+			input:    "**_mixed_**",
+			expected: []span{{"mixed", true, true}},
+		},
+		{
+			name:     "bold with underscores",
+			input:    "__bold__ and *it*",
+			expected: []span{{"bold", true, false}, {" and ", false, false}, {"it", false, true}},
+		},
+		{
+			name:     "unbalanced markers",
+			input:    "**open and *close",
+			expected: []span{{"**", false, false}, {"open and ", false, false}, {"*", false, false}, {"close", false, false}},
+		},
+		{
+			name:     "extra opener",
+			input:    "*a **b*",
+			expected: []span{{"*", false, false}, {"a ", false, false}, {"*", false, false}, {"b", false, true}},
+		},
+		{
+			name:     "snake case",
+			input:    "snake_case_name",
+			expected: []span{{"snake", false, false}, {"_", false, false}, {"case", false, false}, {"_", false, false}, {"name", false, false}},
+		},
+		{
+			name:     "surrounded by spaces",
+			input:    "2 * 3 * 4",
+			expected: []span{{"2 ", false, false}, {"*", false, false}, {" 3 ", false, false}, {"*", false, false}, {" 4", false, false}},
+		},
+		{
+			name:     "inline code",
+			input:    "`*not*` *yes*",
+			expected: []span{{"`*not*` ", false, false}, {"yes", false, true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spans []span
+			for _, token := range matchEmphasis(tokenizeEmphasis(tt.input)) {
+				if token.text != "" {
+					spans = append(spans, span{token.text, token.bold, token.italic})
+				}
+			}
+
+			assert.Equal(t, tt.expected, spans)
+		})
+	}
+
+	m := New("", 80)
+	assert.Equal(t, "**open and *close", m.applyInlineFormatting("**open and *close"))
+	assert.Equal(t, "a b c", m.applyInlineFormatting("**a *b* c**"))
+}
+
+func TestRender_Lists(t *testing.T) {
+	t.Parallel()
+
+	content := "- first\n  * nested **bold**\n    + deeper\n1. one\n10) ten\n-not a list\n```\n- in code\n```"
+
+	m := New(content, 80)
+
+	assert.Equal(t, LineTypeList, m.Lines[1].Type)
+	assert.Equal(t, "  ", m.Lines[1].Indent)
+	assert.Equal(t, "1.", m.Lines[3].ListMarker)
+	assert.Equal(t, LineTypeNormal, m.Lines[5].Type)
+	assert.Equal(t, LineTypeCode, m.Lines[7].Type)
+
+	rendered := m.Render()
+
+	assert.Contains(t, rendered, "• first\n  • nested bold\n    • deeper\n1. one\n10) ten\n-not a list\n")
+	assert.Contains(t, rendered, "  - in code\n")
+}
+
+func TestRender_Quotes(t *testing.T) {
+	t.Parallel()
+
+	m := New("> quoted **text**\n>> nested\n> > spaced\n```\n> in code\n```", 80)
+
+	assert.Equal(t, LineTypeQuote, m.Lines[0].Type)
+	assert.Equal(t, 1, m.Lines[0].QuoteLevel)
+	assert.Equal(t, 2, m.Lines[1].QuoteLevel)
+	assert.Equal(t, "nested", m.Lines[1].Content)
+	assert.Equal(t, 2, m.Lines[2].QuoteLevel)
+	assert.Equal(t, LineTypeCode, m.Lines[4].Type)
+
+	rendered := m.Render()
+
+	assert.Contains(t, rendered, "│ quoted text\n│ │ nested\n│ │ spaced\n")
+	assert.Contains(t, rendered, "  > in code\n")
+}
+
+func TestRender_Rules(t *testing.T) {
+	t.Parallel()
+
+	m := New("---\n* * *\n___\n--\n- item\n| a | b |\n|---|---|\n| 1 | 2 |\n```\n---\n```", 10)
+
+	for _, i := range []int{0, 1, 2} {
+		assert.Equal(t, LineTypeRule, m.Lines[i].Type)
+	}
+
+	assert.Equal(t, LineTypeNormal, m.Lines[3].Type)
+	assert.Equal(t, LineTypeList, m.Lines[4].Type)
+	assert.Equal(t, LineTypeTable, m.Lines[6].Type)
+	assert.Equal(t, LineTypeCode, m.Lines[9].Type)
+
+	rendered := m.Render()
+
+	assert.True(t, strings.HasPrefix(rendered, strings.Repeat(strings.Repeat("─", 10)+"\n", 3)))
+	assert.Contains(t, rendered, "  ---\n")
+}
+
+func TestEstimateVisibleLength_WideCharacters(t *testing.T) {
+	t.Parallel()
+
+	m := New("", 80)
+
+	assert.Equal(t, 6, m.estimateVisibleLength("日本語"))
+	assert.Equal(t, 2, m.estimateVisibleLength("👍"))
+	assert.Equal(t, 2, m.estimateVisibleLength("👨‍👩‍👧"), "A joined emoji sequence is one character")
+	assert.Equal(t, 3, m.estimateVisibleLength("\x1b[1mabc\x1b[0m"))
+}
+
+func TestRender_WrapsWideCharacters(t *testing.T) {
+	t.Parallel()
+
+	content := "これは日本語のテキストです 🎉🎉🎉 and some words 漢字"
+
+	m := New(content, 10)
+
+	lines := strings.Split(strings.TrimSuffix(m.Render(), "\n"), "\n")
+	assert.Greater(t, len(lines), 1)
+
+	for _, line := range lines {
+		assert.LessOrEqual(t, m.estimateVisibleLength(line), 10, "line %q overflows", line)
+	}
+
+	stripSpaces := func(s string) string { return strings.ReplaceAll(s, " ", "") }
+	assert.Equal(t, stripSpaces(content), stripSpaces(strings.Join(lines, "")), "Wrapping should not lose text")
+}
+
+func TestRender_BreaksWordsWiderThanTheLine(t *testing.T) {
+	t.Parallel()
+
+	url := "https://example.com/" + strings.Repeat("a1b2c3d4e5", 6)
+	assert.Len(t, url, 80)
+
+	m := New("see "+url+" here", 40)
+
+	lines := strings.Split(strings.TrimSuffix(m.Render(), "\n"), "\n")
+
+	assert.Equal(t, []string{"see", url[:40], url[40:], "here"}, lines)
+
+	// escape sequences styling the word stay with the text they style
+	m.SetClickableLinks(true)
+	m.SetContent("<" + url + ">")
+
+	for _, line := range strings.Split(strings.TrimSuffix(m.Render(), "\n"), "\n") {
+		assert.LessOrEqual(t, m.estimateVisibleLength(line), 40)
+	}
+}
+
+func TestRender_Footnotes(t *testing.T) {
+	t.Parallel()
+
+	content := "Claim[^src] and another[^2] and a missing one[^nope].\n\n[^2]: Second **note**\n[^src]: The source\nAfter"
+
+	m := New(content, 80)
+
+	assert.Equal(t, LineTypeFootnote, m.Lines[2].Type)
+	assert.Equal(t, "2", m.Lines[2].FootnoteID)
+	assert.Equal(t, map[string]int{"2": 1, "src": 2}, m.Footnotes)
+
+	rendered := m.Render()
+
+	assert.Equal(t, "Claim² and another¹ and a missing one[^nope].\n\nAfter\n\n1. Second note\n2. The source\n", rendered)
+}
+
+func TestMisspellings_SkipsFootnoteReferences(t *testing.T) {
+	t.Parallel()
+
+	m := New("", 80)
+	m.SetDictionary(Dictionary{"see": {}})
+
+	assert.Empty(t, m.misspellings("see[^xyzzy]"))
+}
+
+func TestRender_ClickableLinks(t *testing.T) {
+	t.Parallel()
+
+	m := New("see [docs](https://example.com/docs) and <https://go.dev>", 80)
+
+	assert.Equal(t, "see docs (https://example.com/docs) and https://go.dev\n", m.Render())
+
+	m.SetClickableLinks(true)
+
+	rendered := m.Render()
+
+	assert.Equal(t, "see \x1b]8;;https://example.com/docs\x1b\\docs\x1b]8;;\x1b\\ and \x1b]8;;https://go.dev\x1b\\https://go.dev\x1b]8;;\x1b\\\n", rendered)
+	assert.Equal(t, len("see docs and https://go.dev"), m.estimateVisibleLength(strings.TrimSuffix(rendered, "\n")))
+}
+
+func TestEstimateVisibleLength_SkipsEscapeSequences(t *testing.T) {
+	t.Parallel()
+
+	m := New("", 80)
+
+	assert.Equal(t, 4, m.estimateVisibleLength("\x1b[1m\x1b]8;;https://a.b\x07link\x1b]8;;\x07\x1b[0m"))
+	assert.Equal(t, []string{"\x1b]8;;u\x1b\\ab", "cd\x1b]8;;\x1b\\"}, m.splitByWidth("\x1b]8;;u\x1b\\abcd\x1b]8;;\x1b\\", 2))
+}
+
+func TestRender_Images(t *testing.T) {
+	t.Parallel()
+
+	m := New("![diagram](img/flow.png) next to [a link](https://example.com) and ![](logo.svg \"Logo\")", 80)
+
+	assert.Equal(t, "🖼 diagram (img/flow.png) next to a link (https://example.com) and 🖼 (logo.svg)\n", m.Render())
+}
+
+func TestHeadings_RenderedRow_And_SourceLine(t *testing.T) {
+	t.Parallel()
+
+	content := "# Title\nA long paragraph that wraps over several rows.\n```\n# not a header\n```\n## Section\n[^1]: note\n### Sub"
+
+	m := New(content, 20)
+
+	headings := m.Headings()
+	assert.Equal(t, []Heading{
+		{Level: 1, Text: "Title", Line: 0},
+		{Level: 2, Text: "Section", Line: 5},
+		{Level: 3, Text: "Sub", Line: 7},
+	}, headings)
+
+	rows := strings.Split(m.Render(), "\n")
+
+	for _, h := range headings {
+		assert.Equal(t, h.Text, rows[m.RenderedRow(h.Line)])
+	}
+
+	assert.Equal(t, 0, m.RenderedRow(-1))
+
+	for _, h := range headings {
+		assert.Equal(t, h.Line, m.SourceLine(m.RenderedRow(h.Line)))
+	}
+
+	// a row in the middle of the wrapped paragraph belongs to it
+	assert.Equal(t, 1, m.SourceLine(2))
+}
+
+func TestCodeBlocks(t *testing.T) {
+	t.Parallel()
+
+	m := New("intro\n```go\nx := 1\ny := 2\n```\n\n``` sh \necho hi\n```\n```\nunclosed", 80)
+
+	assert.Equal(t, []CodeBlock{
+		{Language: "go", Code: "x := 1\ny := 2", Line: 1},
+		{Language: "sh", Code: "echo hi", Line: 6},
+	}, m.CodeBlocks())
+}
+
+func TestWikiLinks(t *testing.T) {
+	t.Parallel()
+
+	content := "see [[Ideas]] and [[todo|the list]]\n" +
+		"again [[ideas]], not `[[code]]`\n" +
+		"```\n[[in code]]\n```\n" +
+		"- [[ reading ]]"
+
+	m := New(content, 80)
+	assert.Equal(t, []string{"Ideas", "todo", "reading"}, m.WikiLinks())
+
+	rendered := m.applyInlineFormatting("see [[Ideas]] and [[todo|the list]]")
+	assert.Contains(t, rendered, "Ideas")
+	assert.Contains(t, rendered, "the list")
+	assert.NotContains(t, rendered, "[[")
+	assert.NotContains(t, rendered, "todo")
+
+	assert.Contains(t, m.applyInlineFormatting("`[[code]]`"), "[[code]]")
+}
+
+func TestSetCodeThemes(t *testing.T) {
+	t.Parallel()
+
+	m := New("", 80)
+
+	err := m.SetCodeThemes(map[string]string{"JSON": "github", "javascript": "monokai", "py": "missing"})
+	assert.ErrorContains(t, err, `"missing" for py`)
+
+	json, ok := m.codeTheme("json", lexers.Get("json"))
+	assert.True(t, ok)
+	assert.Equal(t, "github", json.Name)
+
+	js, ok := m.codeTheme("js", lexers.Get("js"))
+	assert.True(t, ok, "the override should apply to the aliases of the language")
+	assert.Equal(t, "monokai", js.Name)
+
+	_, ok = m.codeTheme("go", lexers.Get("go"))
+	assert.False(t, ok)
+
+	code := "{\"a\": 1}\n"
+	plain := New("", 80)
+	assert.NotEqual(t, plain.syntaxHighlightWithChroma(code, "json"), m.syntaxHighlightWithChroma(code, "json"))
+}
+
+func TestSections(t *testing.T) {
+	t.Parallel()
+
+	content := "intro\n" +
+		"<details><summary>Setup</summary>\n" +
+		"install it\n" +
+		"<details>\n<summary>Nested</summary>\n" +
+		"deep\n" +
+		"</details>\n" +
+		"</details>\n" +
+		"<details open>\n" +
+		"shown\n" +
+		"</details>\n" +
+		"outro"
+
+	m := New(content, 80)
+
+	assert.Equal(t, []Section{
+		{Summary: "Setup", Line: 1},
+		{Summary: "Nested", Line: 3, Hidden: true},
+		{Summary: "Details", Line: 8, Expanded: true},
+	}, m.Sections())
+
+	rendered := m.Render()
+	assert.Contains(t, rendered, "Setup")
+	assert.Contains(t, rendered, "shown")
+	assert.Contains(t, rendered, "outro")
+	assert.NotContains(t, rendered, "install it")
+	assert.NotContains(t, rendered, "Nested")
+	assert.NotContains(t, rendered, "details>")
+
+	m.ToggleSection(1)
+	rendered = m.Render()
+	assert.Contains(t, rendered, "install it")
+	assert.Contains(t, rendered, "Nested")
+	assert.NotContains(t, rendered, "deep")
+
+	// the toggled state holds while the content is the same
+	m.SetContent(content)
+	assert.True(t, m.Sections()[0].Expanded)
+
+	m.SetContent(content + "\n")
+	assert.False(t, m.Sections()[0].Expanded)
+}
+
+func TestDefinitionLists(t *testing.T) {
+	t.Parallel()
+
+	m := New("Term\n: first\n: second\n\n: not a definition", 80)
+
+	types := make([]LineType, len(m.Lines))
+	for i, line := range m.Lines {
+		types[i] = line.Type
+	}
+
+	assert.Equal(t, []LineType{LineTypeTerm, LineTypeDefinition, LineTypeDefinition, LineTypeEmpty, LineTypeNormal}, types)
+
+	rendered := m.Render()
+	assert.Contains(t, rendered, "    first")
+	assert.NotContains(t, rendered, ": second")
+}
+
+func TestParseLines_CodeBlocks(t *testing.T) {
+	t.Parallel()
+
+	content := "````markdown\n" +
+		"```go\n" +
+		"x := 1\n" +
+		"```\n" +
+		"````\n" +
+		"text\n" +
+		"    not code, continues the paragraph\n" +
+		"\n" +
+		"    indented **code**\n" +
+		"\tmore code\n" +
+		"- item\n" +
+		"\n" +
+		"    continues the item"
+
+	m := New(content, 80)
+
+	types := make([]LineType, len(m.Lines))
+	for i, line := range m.Lines {
+		types[i] = line.Type
+	}
+
+	assert.Equal(t, []LineType{
+		LineTypeCodeFence, LineTypeCode, LineTypeCode, LineTypeCode, LineTypeCodeFence,
+		LineTypeNormal, LineTypeNormal, LineTypeEmpty,
+		LineTypeCode, LineTypeCode,
+		LineTypeList, LineTypeEmpty, LineTypeNormal,
+	}, types)
+
+	assert.Equal(t, "markdown", m.Lines[0].CodeLang)
+	assert.Equal(t, "indented **code**", m.Lines[8].Content)
+	assert.Equal(t, "more code", m.Lines[9].Content)
+
+	assert.Equal(t, []CodeBlock{{Language: "markdown", Code: "```go\nx := 1\n```", Line: 0}}, m.CodeBlocks())
+
+	m.SetNoColor(true)
+	assert.Contains(t, m.Render(), "indented **code**")
+}
+
+func TestPlainText(t *testing.T) {
+	t.Parallel()
+
+	content := "## Setup *now*\n" +
+		"- [x] install [[tools|the tools]]\n" +
+		"1. read <https://go.dev>[^1]\n" +
+		"  * ~~old~~ ![logo](logo.png)\n" +
+		"> quoted __text__\n" +
+		"---\n" +
+		"```sh\n" +
+		"echo **raw**\n" +
+		"```\n" +
+		"[^1]: the *docs*"
+
+	assert.Equal(t, "Setup now\n"+
+		"☑ install the tools\n"+
+		"1. read https://go.dev\n"+
+		"  • ~~old~~ logo\n"+
+		"quoted text\n"+
+		"\n"+
+		"echo **raw**\n"+
+		"[1] the docs", New(content, 80).PlainText())
+}
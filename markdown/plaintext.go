@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/ionut-t/notes/internal/utils"
+)
+
+// PlainText returns the content without its markdown syntax: headers,
+// emphasis, code fences and backticks are dropped, links are reduced to
+// their text and list markers to bullets, while code keeps its spacing
+func (m Model) PlainText() string {
+	var lines []string
+
+	for _, line := range m.Lines {
+		switch line.Type {
+		case LineTypeCodeFence, LineTypeSummary, LineTypeDetailsEnd:
+			continue
+
+		case LineTypeCode, LineTypeTable:
+			lines = append(lines, line.Content)
+
+		case LineTypeRule, LineTypeEmpty:
+			lines = append(lines, "")
+
+		case LineTypeTask:
+			lines = append(lines, line.Indent+utils.Ternary(line.Checked, "☑ ", "☐ ")+stripInline(line.Content))
+
+		case LineTypeList:
+			marker := utils.Ternary(line.ListMarker == "", "•", line.ListMarker)
+			lines = append(lines, line.Indent+marker+" "+stripInline(line.Content))
+
+		case LineTypeFootnote:
+			lines = append(lines, "["+line.FootnoteID+"] "+stripInline(line.Content))
+
+		case LineTypeDetails:
+			lines = append(lines, stripInline(line.Summary))
+
+		case LineTypeDefinition:
+			lines = append(lines, "    "+stripInline(strings.TrimSpace(line.Content[2:])))
+
+		default:
+			lines = append(lines, stripInline(line.Content))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// stripInline removes the inline markdown of the text, leaving the code
+// spans as they are written without their backticks
+func stripInline(text string) string {
+	var result strings.Builder
+
+	last := 0
+	for _, span := range inlineCodeRegex.FindAllStringIndex(text, -1) {
+		result.WriteString(stripInlineMarkup(text[last:span[0]]))
+		result.WriteString(text[span[0]+1 : span[1]-1])
+		last = span[1]
+	}
+
+	result.WriteString(stripInlineMarkup(text[last:]))
+
+	return result.String()
+}
+
+// stripInlineMarkup reduces the links and images to their text
+// and removes the footnote references and emphasis markers
+func stripInlineMarkup(text string) string {
+	text = footnoteReferenceRegex.ReplaceAllString(text, "")
+	text = urlAutolinkRegex.ReplaceAllString(text, "$1")
+	text = mailAutolinkRegex.ReplaceAllString(text, "$1")
+
+	text = wikiLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := wikiLinkRegex.FindStringSubmatch(match)
+		return strings.TrimSpace(utils.Ternary(parts[2] == "", parts[1], parts[2]))
+	})
+
+	text = imageRegex.ReplaceAllString(text, "$1")
+	text = linkRegex.ReplaceAllString(text, "$1")
+
+	var result strings.Builder
+
+	for _, t := range matchEmphasis(tokenizeEmphasis(text)) {
+		result.WriteString(t.text)
+	}
+
+	return result.String()
+}
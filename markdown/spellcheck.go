@@ -0,0 +1,153 @@
+package markdown
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ionut-t/notes/styles"
+)
+
+var (
+	// spellcheckSkipRegex matches the parts of a line that are never spellchecked:
+	// inline code, links, footnote references, autolinks and bare urls
+	spellcheckSkipRegex = regexp.MustCompile("`[^`]+`|\\[\\[[^\\]]*\\]\\]|\\[[^\\]]*\\]\\([^)]*\\)|\\[\\^[^\\]]+\\]|<[^\\s<>]+>|(?:https?|ftp)://\\S+")
+	wordRegex           = regexp.MustCompile(`[\p{L}]+(?:'[\p{L}]+)*`)
+)
+
+// Range is a misspelled word of the content: the index of its line
+// and the byte offsets of the word in that line
+type Range struct {
+	Line  int
+	Start int
+	End   int
+}
+
+// Dictionary is a set of correctly spelled words, stored in lowercase
+type Dictionary map[string]struct{}
+
+// LoadDictionary reads a wordlist with one word per line, such as
+// /usr/share/dict/words, and adds the ignored words to it
+func LoadDictionary(path string, ignore []string) (Dictionary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dictionary := make(Dictionary)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			dictionary[strings.ToLower(word)] = struct{}{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, word := range ignore {
+		dictionary[strings.ToLower(word)] = struct{}{}
+	}
+
+	return dictionary, nil
+}
+
+// Contains reports whether the word, or the word without
+// a possessive suffix, is in the dictionary
+func (d Dictionary) Contains(word string) bool {
+	word = strings.ToLower(word)
+
+	if _, ok := d[word]; ok {
+		return true
+	}
+
+	_, ok := d[strings.TrimSuffix(word, "'s")]
+	return ok
+}
+
+// SetDictionary enables the spellcheck of the rendered prose.
+// A nil dictionary disables it.
+func (m *Model) SetDictionary(dictionary Dictionary) {
+	m.Dictionary = dictionary
+}
+
+// misspellings returns the byte ranges of the words missing from the dictionary,
+// ignoring inline code, links and single letters
+func (m *Model) misspellings(text string) [][]int {
+	if m.Dictionary == nil {
+		return nil
+	}
+
+	var ranges [][]int
+
+	checkWords := func(start, end int) {
+		for _, loc := range wordRegex.FindAllStringIndex(text[start:end], -1) {
+			word := text[start+loc[0] : start+loc[1]]
+
+			if len([]rune(word)) > 1 && !m.Dictionary.Contains(word) {
+				ranges = append(ranges, []int{start + loc[0], start + loc[1]})
+			}
+		}
+	}
+
+	last := 0
+	for _, loc := range spellcheckSkipRegex.FindAllStringIndex(text, -1) {
+		checkWords(last, loc[0])
+		last = loc[1]
+	}
+
+	checkWords(last, len(text))
+
+	return ranges
+}
+
+// Misspellings returns the words of the content missing from the dictionary,
+// skipping fenced and indented code, inline code and links
+func (m *Model) Misspellings(content string) []Range {
+	if m.Dictionary == nil {
+		return nil
+	}
+
+	checked := Model{Content: content}
+	checked.ParseLines()
+
+	var ranges []Range
+	for i, text := range strings.Split(content, "\n") {
+		switch checked.Lines[i].Type {
+		case LineTypeCodeFence, LineTypeCode, LineTypeComment, LineTypeEmpty, LineTypeRule:
+			continue
+		}
+
+		// offsets are taken in the source line, which parsing trims for headers
+		for _, r := range m.misspellings(text) {
+			ranges = append(ranges, Range{Line: i, Start: r[0], End: r[1]})
+		}
+	}
+
+	return ranges
+}
+
+// highlightMisspellings underlines the misspelled words of the text
+func (m *Model) highlightMisspellings(text string) string {
+	ranges := m.misspellings(text)
+	if len(ranges) == 0 {
+		return text
+	}
+
+	var result strings.Builder
+
+	last := 0
+	for _, r := range ranges {
+		result.WriteString(text[last:r[0]])
+		result.WriteString(styles.Error.Underline(true).Render(text[r[0]:r[1]]))
+		last = r[1]
+	}
+
+	result.WriteString(text[last:])
+
+	return result.String()
+}
@@ -0,0 +1,221 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ionut-t/notes/styles"
+)
+
+const (
+	tableColumnSeparator = " │ "
+	tableMinColumnWidth  = 3
+)
+
+var tableSeparatorCellRegex = regexp.MustCompile(`^:?-+:?$`)
+
+// isTableRow reports whether the line looks like a pipe table row
+func isTableRow(content string) bool {
+	trimmed := strings.TrimSpace(content)
+	return strings.HasPrefix(trimmed, "|") && strings.Count(trimmed, "|") > 1
+}
+
+// isTableSeparator reports whether the line is the row separating
+// the table header from its body, such as |---|:--:|
+func isTableSeparator(content string) bool {
+	if !isTableRow(content) {
+		return false
+	}
+
+	for _, cell := range splitTableRow(content) {
+		if !tableSeparatorCellRegex.MatchString(strings.ReplaceAll(cell, " ", "")) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitTableRow returns the trimmed cells of a table row, keeping escaped pipes
+func splitTableRow(content string) []string {
+	row := strings.TrimSpace(content)
+	row = strings.TrimPrefix(row, "|")
+	row = strings.TrimSuffix(row, "|")
+
+	var cells []string
+	var cell strings.Builder
+
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// markTables sets the type of the lines forming a table: a header row
+// followed by a separator row and any number of body rows
+func (m *Model) markTables() {
+	for i := 0; i < len(m.Lines); i++ {
+		if m.Lines[i].Type != LineTypeNormal || !isTableRow(m.Lines[i].Content) ||
+			i+1 >= len(m.Lines) || !isTableSeparator(m.Lines[i+1].Content) {
+			continue
+		}
+
+		end := i + 2
+		for end < len(m.Lines) && m.Lines[end].Type == LineTypeNormal && isTableRow(m.Lines[end].Content) {
+			end++
+		}
+
+		for j := i; j < end; j++ {
+			m.Lines[j].Type = LineTypeTable
+		}
+
+		i = end - 1
+	}
+}
+
+// renderTables renders every table of the content, returning the
+// rendered row of each table line keyed by the line index
+func (m *Model) renderTables(width int) map[int]string {
+	rows := make(map[int]string)
+
+	for i := 0; i < len(m.Lines); i++ {
+		if m.Lines[i].Type != LineTypeTable {
+			continue
+		}
+
+		end := i
+		for end < len(m.Lines) && m.Lines[end].Type == LineTypeTable {
+			end++
+		}
+
+		for j, row := range m.renderTable(m.Lines[i:end], width) {
+			rows[i+j] = row
+		}
+
+		i = end - 1
+	}
+
+	return rows
+}
+
+// renderTable aligns the cells of a table in columns sized to the widest
+// cell, shrinking the widest columns when the table doesn't fit the width
+func (m *Model) renderTable(lines []Line, width int) []string {
+	alignments := parseTableAlignments(lines[1].Content)
+	columns := len(alignments)
+
+	cells := make([][]string, len(lines))
+	for i, line := range lines {
+		if i == 1 {
+			continue
+		}
+
+		row := splitTableRow(line.Content)
+		cells[i] = make([]string, columns)
+
+		for j := range min(len(row), columns) {
+			cells[i][j] = m.applyInlineFormatting(row[j])
+		}
+	}
+
+	widths := make([]int, columns)
+	for i, row := range cells {
+		if i == 1 {
+			continue
+		}
+
+		for j, cell := range row {
+			widths[j] = max(widths[j], m.estimateVisibleLength(cell), tableMinColumnWidth)
+		}
+	}
+
+	fitColumns(widths, width-(columns-1)*lipgloss.Width(tableColumnSeparator))
+
+	separator := styles.Subtext0.Render(tableColumnSeparator)
+	rendered := make([]string, len(lines))
+
+	for i, row := range cells {
+		parts := make([]string, columns)
+
+		for j := range columns {
+			if i == 1 {
+				parts[j] = styles.Subtext0.Render(strings.Repeat("─", widths[j]))
+				continue
+			}
+
+			cell := ansi.Truncate(row[j], widths[j], "…")
+			if i == 0 {
+				cell = styles.Text.Bold(true).Render(cell)
+			}
+
+			parts[j] = lipgloss.PlaceHorizontal(widths[j], alignments[j], cell)
+		}
+
+		if i == 1 {
+			rendered[i] = strings.Join(parts, styles.Subtext0.Render("─┼─"))
+		} else {
+			rendered[i] = strings.Join(parts, separator)
+		}
+	}
+
+	return rendered
+}
+
+// parseTableAlignments reads the alignment of each column from the separator row
+func parseTableAlignments(separator string) []lipgloss.Position {
+	cells := splitTableRow(separator)
+	alignments := make([]lipgloss.Position, len(cells))
+
+	for i, cell := range cells {
+		cell = strings.ReplaceAll(cell, " ", "")
+		left := strings.HasPrefix(cell, ":")
+		right := strings.HasSuffix(cell, ":")
+
+		switch {
+		case left && right:
+			alignments[i] = lipgloss.Center
+		case right:
+			alignments[i] = lipgloss.Right
+		default:
+			alignments[i] = lipgloss.Left
+		}
+	}
+
+	return alignments
+}
+
+// fitColumns shrinks the widest columns until their total fits the available width
+func fitColumns(widths []int, available int) {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > available {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+
+		if widths[widest] <= tableMinColumnWidth {
+			return
+		}
+
+		widths[widest]--
+		total--
+	}
+}
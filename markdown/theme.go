@@ -1,6 +1,10 @@
 package markdown
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/alecthomas/chroma"
 	"github.com/alecthomas/chroma/styles"
 )
@@ -124,3 +128,68 @@ func (m *Model) SetStyle(styleName string) {
 		m.ChromaStyle = style
 	}
 }
+
+// SetTheme applies a theme by name: "dark" and "light" pick the matching
+// Catppuccin flavour, any other name must be a registered Chroma style
+func (m *Model) SetTheme(name string) error {
+	if name == "dark" || name == "light" {
+		m.SetCatppuccinTheme(name)
+		return nil
+	}
+
+	if _, ok := styles.Registry[name]; !ok {
+		return fmt.Errorf("unknown theme %q, expected dark, light or a Chroma style such as monokai", name)
+	}
+
+	m.SetStyle(name)
+
+	return nil
+}
+
+// SetCodeThemes overrides the style of the code blocks of some languages,
+// mapping a language to the name of a Chroma style. Every known style is
+// applied even when another one is unknown and reported in the error.
+func (m *Model) SetCodeThemes(themes map[string]string) error {
+	m.CodeThemes = make(map[string]*chroma.Style, len(themes))
+
+	var unknown []string
+
+	for language, name := range themes {
+		style, ok := styles.Registry[name]
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("%q for %s", name, language))
+			continue
+		}
+
+		m.CodeThemes[strings.ToLower(strings.TrimSpace(language))] = style
+	}
+
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("unknown code themes %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// codeTheme returns the style overriding the theme for the language
+// of a code block, named as in the fence or by the lexer found for it
+func (m *Model) codeTheme(language string, lexer chroma.Lexer) (*chroma.Style, bool) {
+	if style, ok := m.CodeThemes[language]; ok {
+		return style, true
+	}
+
+	if lexer == nil {
+		return nil, false
+	}
+
+	config := lexer.Config()
+
+	for _, name := range append([]string{config.Name}, config.Aliases...) {
+		if style, ok := m.CodeThemes[strings.ToLower(name)]; ok {
+			return style, true
+		}
+	}
+
+	return nil, false
+}
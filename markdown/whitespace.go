@@ -0,0 +1,47 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ionut-t/notes/styles"
+)
+
+// RevealWhitespace shows the whitespace of the content as it's written,
+// spaces as middots and tabs as arrows padded to the next tab stop. The
+// trailing whitespace of each line, such as the two spaces of a line
+// break, is highlighted.
+func RevealWhitespace(content string, tabWidth int) string {
+	lines := strings.Split(content, "\n")
+
+	for i, line := range lines {
+		text := strings.TrimRight(line, " \t")
+
+		var result strings.Builder
+		column := 0
+
+		reveal := func(s string, style lipgloss.Style) {
+			for _, r := range s {
+				switch r {
+				case ' ':
+					result.WriteString(style.Render("·"))
+					column++
+				case '\t':
+					width := max(tabWidth-column%max(tabWidth, 1), 1)
+					result.WriteString(style.Render("→" + strings.Repeat(" ", width-1)))
+					column += width
+				default:
+					result.WriteRune(r)
+					column++
+				}
+			}
+		}
+
+		reveal(text, styles.Overlay0)
+		reveal(line[len(text):], styles.Warning)
+
+		lines[i] = result.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ionut-t/notes/styles"
+)
+
+// wikiLinkRegex matches [[note]] and [[note|label]] links to other notes
+var wikiLinkRegex = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]+))?\]\]`)
+
+// applyWikiLinks renders the links to other notes by their label,
+// or the name of the note, leaving inline code untouched
+func (m *Model) applyWikiLinks(text string) string {
+	var result strings.Builder
+
+	last := 0
+	for _, loc := range inlineCodeRegex.FindAllStringIndex(text, -1) {
+		result.WriteString(replaceWikiLinks(text[last:loc[0]]))
+		result.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+
+	result.WriteString(replaceWikiLinks(text[last:]))
+
+	return result.String()
+}
+
+func replaceWikiLinks(text string) string {
+	return wikiLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		parts := wikiLinkRegex.FindStringSubmatch(match)
+		label := strings.TrimSpace(parts[2])
+		if label == "" {
+			label = strings.TrimSpace(parts[1])
+		}
+
+		return styles.Primary.Underline(true).Render(label)
+	})
+}
+
+// WikiLinks returns the names of the notes linked with [[note]] in the
+// content, once each in the order they first appear. Links in code
+// blocks, inline code and comments don't count.
+func (m Model) WikiLinks() []string {
+	var links []string
+	seen := make(map[string]bool)
+
+	for _, line := range m.Lines {
+		switch line.Type {
+		case LineTypeCode, LineTypeCodeFence, LineTypeComment:
+			continue
+		}
+
+		text := inlineCodeRegex.ReplaceAllString(line.Content, "")
+
+		for _, parts := range wikiLinkRegex.FindAllStringSubmatch(text, -1) {
+			name := strings.TrimSpace(parts[1])
+			if name == "" || seen[strings.ToLower(name)] {
+				continue
+			}
+
+			seen[strings.ToLower(name)] = true
+			links = append(links, name)
+		}
+	}
+
+	return links
+}
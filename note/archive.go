@@ -0,0 +1,58 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const archiveDir = ".archive"
+
+func (s Store) getArchivePath(name string) string {
+	return filepath.Join(s.storage, archiveDir, name+s.extension)
+}
+
+// Archive moves the note into the archive directory, under the same
+// folders, taking it out of the notes without deleting it
+func (s *Store) Archive(name string) error {
+	if _, ok := s.notesDictionary[noteKey(name)]; !ok {
+		return errors.New("note not found")
+	}
+
+	if _, err := s.moveInto(archiveDir, s.GetNotePath(name)); err != nil {
+		return fmt.Errorf("failed to archive note %s: %w", name, err)
+	}
+
+	s.notes = slices.DeleteFunc(s.notes, func(n Note) bool {
+		return n.Name == name
+	})
+
+	delete(s.notesDictionary, noteKey(name))
+	s.indexAliases()
+
+	return nil
+}
+
+// ListArchived returns the archived notes, most recently modified first
+func (s *Store) ListArchived() []Note {
+	return s.listNotesIn(archiveDir)
+}
+
+// Unarchive moves a note out of the archive, back into its folder,
+// and makes it the current note.
+// It gets a suffix if a note with the same name was created since.
+func (s *Store) Unarchive(name string) error {
+	archivePath := s.getArchivePath(name)
+
+	if _, err := os.Stat(archivePath); err != nil {
+		return fmt.Errorf("failed to find %s in the archive: %w", name, err)
+	}
+
+	if err := s.moveBack(archivePath, name); err != nil {
+		return fmt.Errorf("failed to unarchive note %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package note
+
+import "github.com/ionut-t/notes/markdown"
+
+// Backlinks returns the names of the notes linking to the named note with
+// [[name]], or with one of its aliases, in the order of the notes
+func (s Store) Backlinks(name string) []string {
+	name, ok := s.ResolveName(name)
+	if !ok {
+		return nil
+	}
+
+	var backlinks []string
+
+	for _, note := range s.notes {
+		if noteKey(note.Name) == noteKey(name) {
+			continue
+		}
+
+		for _, link := range markdown.New(note.Content, 0).WikiLinks() {
+			if target, ok := s.ResolveName(link); ok && noteKey(target) == noteKey(name) {
+				backlinks = append(backlinks, note.Name)
+				break
+			}
+		}
+	}
+
+	return backlinks
+}
@@ -0,0 +1,196 @@
+package note
+
+import (
+	"strings"
+	"unicode"
+)
+
+type DiffKind int
+
+const (
+	DiffEqual DiffKind = iota
+	DiffInsert
+	DiffDelete
+)
+
+// DiffOp is a single chunk of a diff between two versions of a text
+type DiffOp struct {
+	Kind DiffKind
+	Text string
+}
+
+// WordDiff compares two texts word by word, keeping whitespace intact
+// so that concatenating every op of a side rebuilds that side exactly.
+// Texts too far apart to compare word by word are compared line by line.
+func WordDiff(oldText, newText string) []DiffOp {
+	a, b := splitWords(oldText), splitWords(newText)
+
+	prefix, suffix := commonAffixes(a, b)
+	if (len(a)-prefix-suffix+1)*(len(b)-prefix-suffix+1) > maxDiffCells {
+		return LineDiff(oldText, newText)
+	}
+
+	return diffTokens(a, b)
+}
+
+// LineDiff compares two texts line by line. Every op holds whole lines,
+// each ending with a newline.
+func LineDiff(oldText, newText string) []DiffOp {
+	return diffTokens(splitLines(oldText), splitLines(newText))
+}
+
+// LineChanges counts the lines inserted and deleted by a line diff
+func LineChanges(ops []DiffOp) (inserted, deleted int) {
+	for _, op := range ops {
+		switch op.Kind {
+		case DiffInsert:
+			inserted += strings.Count(op.Text, "\n")
+		case DiffDelete:
+			deleted += strings.Count(op.Text, "\n")
+		}
+	}
+
+	return inserted, deleted
+}
+
+// splitLines splits text into lines ending with a newline,
+// ignoring whether the text itself ends with one
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i := range lines {
+		lines[i] += "\n"
+	}
+
+	return lines
+}
+
+// splitWords splits text into alternating runs of whitespace and non-whitespace
+func splitWords(text string) []string {
+	var tokens []string
+
+	start := 0
+	inSpace := false
+
+	for i, r := range text {
+		isSpace := unicode.IsSpace(r)
+
+		if i > start && isSpace != inSpace {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+
+		inSpace = isSpace
+	}
+
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+
+	return tokens
+}
+
+// maxDiffCells caps the size of the LCS table, above which a diff
+// replaces the whole changed middle instead of matching tokens in it
+const maxDiffCells = 4 << 20
+
+// commonAffixes returns the number of tokens shared by the start
+// and, after that, by the end of both slices
+func commonAffixes(a, b []string) (prefix, suffix int) {
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	return prefix, suffix
+}
+
+// diffTokens computes the longest common subsequence of two token slices
+// and turns it into a list of equal, inserted and deleted chunks
+func diffTokens(a, b []string) []DiffOp {
+	var ops []DiffOp
+
+	appendOp := func(kind DiffKind, text string) {
+		if n := len(ops); n > 0 && ops[n-1].Kind == kind {
+			ops[n-1].Text += text
+			return
+		}
+
+		ops = append(ops, DiffOp{Kind: kind, Text: text})
+	}
+
+	prefix, suffix := commonAffixes(a, b)
+
+	for _, token := range a[:prefix] {
+		appendOp(DiffEqual, token)
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	if (len(midA)+1)*(len(midB)+1) > maxDiffCells {
+		for _, token := range midA {
+			appendOp(DiffDelete, token)
+		}
+
+		for _, token := range midB {
+			appendOp(DiffInsert, token)
+		}
+	} else {
+		diffLCS(midA, midB, appendOp)
+	}
+
+	for _, token := range a[len(a)-suffix:] {
+		appendOp(DiffEqual, token)
+	}
+
+	return ops
+}
+
+// diffLCS diffs two token slices through their longest common subsequence
+func diffLCS(a, b []string, appendOp func(DiffKind, string)) {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			appendOp(DiffEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			appendOp(DiffDelete, a[i])
+			i++
+		default:
+			appendOp(DiffInsert, b[j])
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		appendOp(DiffDelete, a[i])
+	}
+
+	for ; j < len(b); j++ {
+		appendOp(DiffInsert, b[j])
+	}
+}
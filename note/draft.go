@@ -0,0 +1,75 @@
+package note
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const draftsDir = ".drafts"
+
+func (s Store) getDraftPath(name string) string {
+	return filepath.Join(s.storage, draftsDir, name+s.extension)
+}
+
+// SaveDraft writes the unsaved content of a note next to the notes,
+// from where it can be restored if the changes are lost
+func (s Store) SaveDraft(name, content string) error {
+	if err := os.MkdirAll(filepath.Dir(s.getDraftPath(name)), 0755); err != nil {
+		return err
+	}
+
+	data, err := s.sealNote(content)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.getDraftPath(name), data)
+}
+
+// GetDraft returns the draft of the note when it was written after
+// the note was last saved and differs from it
+func (s Store) GetDraft(name string) (string, bool) {
+	draftInfo, err := os.Stat(s.getDraftPath(name))
+	if err != nil {
+		return "", false
+	}
+
+	noteInfo, err := os.Stat(s.GetNotePath(name))
+	if err == nil && !draftInfo.ModTime().After(noteInfo.ModTime()) {
+		return "", false
+	}
+
+	data, err := s.readNote(s.getDraftPath(name))
+	if err != nil {
+		return "", false
+	}
+
+	if note, ok := s.notesDictionary[noteKey(name)]; ok && note.Content == string(data) {
+		return "", false
+	}
+
+	return string(data), true
+}
+
+// moveDraft keeps the draft of a note after it is renamed, if there is one
+func (s Store) moveDraft(currentName, newName string) error {
+	if _, err := os.Stat(s.getDraftPath(currentName)); err != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.getDraftPath(newName)), 0755); err != nil {
+		return err
+	}
+
+	return os.Rename(s.getDraftPath(currentName), s.getDraftPath(newName))
+}
+
+// DeleteDraft removes the draft of the note, if there is one
+func (s Store) DeleteDraft(name string) error {
+	if err := os.Remove(s.getDraftPath(name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
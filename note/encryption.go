@@ -0,0 +1,218 @@
+package note
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptionFile holds the salt the key of the notes is derived from,
+// along with a value encrypted with it to check the passphrase
+const encryptionFile = ".encryption"
+
+// encryptedHeader starts the encrypted notes, followed by the nonce and
+// the sealed content encoded in base64, which tells them apart from
+// plaintext notes in the same storage
+const encryptedHeader = "notes-encrypted:v1\n"
+
+// passphraseCheck is encrypted into the encryption file to tell
+// a wrong passphrase apart when the notes are unlocked
+const passphraseCheck = "notes"
+
+// ErrWrongPassphrase is returned when the passphrase doesn't
+// match the one the notes were encrypted with
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+type encryptionParams struct {
+	Salt  []byte `json:"salt"`
+	Check []byte `json:"check"`
+}
+
+// HasPassphrase reports whether a passphrase was set for the notes,
+// by unlocking them once with encryption enabled
+func (s Store) HasPassphrase() bool {
+	_, err := os.Stat(filepath.Join(s.storage, encryptionFile))
+	return err == nil
+}
+
+// IsEncrypted reports whether the notes are unlocked and saved encrypted
+func (s Store) IsEncrypted() bool {
+	return s.key != nil
+}
+
+// Unlock derives the key of the notes from the passphrase, after which
+// they are saved encrypted and the encrypted ones can be read. The first
+// passphrase becomes the passphrase of the notes.
+func (s *Store) Unlock(passphrase string) error {
+	if passphrase == "" {
+		return errors.New("the passphrase cannot be empty")
+	}
+
+	path := filepath.Join(s.storage, encryptionFile)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s.setPassphrase(path, passphrase)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read encryption file: %w", err)
+	}
+
+	var params encryptionParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		return fmt.Errorf("failed to parse encryption file: %w", err)
+	}
+
+	key, err := deriveKey(passphrase, params.Salt)
+	if err != nil {
+		return err
+	}
+
+	check, err := decrypt(key, params.Check)
+	if err != nil || string(check) != passphraseCheck {
+		return ErrWrongPassphrase
+	}
+
+	s.key = key
+
+	return nil
+}
+
+func (s *Store) setPassphrase(path, passphrase string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	check, err := encrypt(key, []byte(passphraseCheck))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(encryptionParams{Salt: salt, Check: check}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.storage, 0755); err != nil {
+		return fmt.Errorf("failed to create notes directory: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write encryption file: %w", err)
+	}
+
+	s.key = key
+
+	return nil
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+// sealNote returns the content of a note as it's written to disk,
+// encrypted when the notes are unlocked
+func (s Store) sealNote(content string) ([]byte, error) {
+	if s.key == nil {
+		return []byte(content), nil
+	}
+
+	return encrypt(s.key, []byte(content))
+}
+
+// readNote reads a note from disk, decrypting it when it's encrypted
+func (s Store) readNote(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, []byte(encryptedHeader)) {
+		return data, nil
+	}
+
+	if s.key == nil {
+		return nil, fmt.Errorf("%s is encrypted, enable encryption to read it", filepath.Base(path))
+	}
+
+	content, err := decrypt(s.key, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", filepath.Base(path), err)
+	}
+
+	return content, nil
+}
+
+// encrypt seals the plaintext with AES-GCM under a random nonce
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	return []byte(encryptedHeader + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// decrypt opens data written by encrypt, failing with ErrWrongPassphrase
+// when it was encrypted with another key or was modified since
+func decrypt(key, data []byte) ([]byte, error) {
+	encoded, ok := bytes.CutPrefix(data, []byte(encryptedHeader))
+	if !ok {
+		return nil, errors.New("missing encryption header")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted content: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("malformed encrypted content")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,38 @@
+package note_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ionut-t/notes/note"
+)
+
+func ExampleNewStoreWithConfig() {
+	dir, err := os.MkdirTemp("", "notes")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := note.NewStoreWithConfig(dir, "vim")
+
+	if _, err := store.LoadNotes(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := store.Create("groceries", "---\ntitle: Groceries\ntags: [home]\n---\n\n- milk"); err != nil {
+		log.Fatal(err)
+	}
+
+	notes, err := store.LoadNotes()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, n := range notes {
+		fmt.Println(n.Name, n.Title(), n.Tags)
+	}
+
+	// Output: groceries Groceries [home]
+}
@@ -0,0 +1,67 @@
+package note
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const frontmatterDelimiter = "---"
+
+// frontmatter holds the metadata declared in the YAML block at the top of a note
+type frontmatter struct {
+	Title   string   `yaml:"title"`
+	Aliases []string `yaml:"aliases"`
+	Tags    []string `yaml:"tags"`
+}
+
+// splitFrontmatter separates the YAML frontmatter block delimited by "---"
+// from the rest of the content
+func splitFrontmatter(content string) (string, string, bool) {
+	lines := strings.Split(content, "\n")
+
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != frontmatterDelimiter {
+		return "", content, false
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == frontmatterDelimiter {
+			return strings.Join(lines[1:i], "\n"), strings.Join(lines[i+1:], "\n"), true
+		}
+	}
+
+	return "", content, false
+}
+
+// parseFrontmatter extracts the metadata of a note, ignoring
+// frontmatter blocks that aren't valid YAML
+func parseFrontmatter(content string) frontmatter {
+	var fm frontmatter
+
+	block, _, ok := splitFrontmatter(content)
+	if !ok {
+		return fm
+	}
+
+	if err := yaml.Unmarshal([]byte(block), &fm); err != nil {
+		return frontmatter{}
+	}
+
+	return fm
+}
+
+// Body returns the content of the note without its frontmatter block,
+// which is what gets rendered
+func (n Note) Body() string {
+	if _, body, ok := splitFrontmatter(n.Content); ok {
+		return strings.TrimLeft(body, "\n")
+	}
+
+	return n.Content
+}
+
+// BodyLine returns the line of the content the body starts on, 1 without
+// frontmatter, so that the lines of the body can be numbered as in the file
+func (n Note) BodyLine() int {
+	return strings.Count(n.Content, "\n") - strings.Count(n.Body(), "\n") + 1
+}
@@ -0,0 +1,67 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrAutoCommit is wrapped by the errors of changes that were saved
+// but could not be committed to the git repository of the storage
+var ErrAutoCommit = errors.New("git auto-commit failed")
+
+// notesPathspec limits the commits to the notes. Drafts are autosaved while
+// editing, and the config and layout of the UI live in the default storage
+// without being notes, so none of them belong in the history.
+var notesPathspec = []string{
+	"--", ".",
+	":(exclude)" + draftsDir,
+	":(exclude).config.toml",
+	":(exclude).state.json",
+}
+
+// commit records the changes of the storage in its git repository when
+// git_auto_commit is enabled. It does nothing when the storage isn't
+// inside a git repository or nothing changed.
+func (s Store) commit(message string) error {
+	if !s.gitAutoCommit {
+		return nil
+	}
+
+	if _, err := s.git("rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil
+	}
+
+	if _, err := s.git(append([]string{"add", "--all"}, notesPathspec...)...); err != nil {
+		return fmt.Errorf("%w: %w", ErrAutoCommit, err)
+	}
+
+	// nothing staged, for example when a note is saved unchanged
+	if _, err := s.git(append([]string{"diff", "--cached", "--quiet"}, notesPathspec...)...); err == nil {
+		return nil
+	}
+
+	if _, err := s.git(append([]string{"commit", "--message", message}, notesPathspec...)...); err != nil {
+		return fmt.Errorf("%w: %w", ErrAutoCommit, err)
+	}
+
+	return nil
+}
+
+// git runs a git command in the storage directory, returning
+// its output as the error when it fails
+func (s Store) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", s.storage}, args...)...)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return "", errors.New(message)
+		}
+
+		return "", err
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,117 @@
+package note
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CollisionPolicy decides what happens when an imported file
+// has the same name as an existing note
+type CollisionPolicy string
+
+const (
+	CollisionDedupe    CollisionPolicy = "dedupe"
+	CollisionSkip      CollisionPolicy = "skip"
+	CollisionOverwrite CollisionPolicy = "overwrite"
+)
+
+func ParseCollisionPolicy(value string) (CollisionPolicy, error) {
+	switch policy := CollisionPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return CollisionDedupe, nil
+	case CollisionDedupe, CollisionSkip, CollisionOverwrite:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid collision policy %q, expected dedupe, skip or overwrite", value)
+	}
+}
+
+type ImportAction string
+
+const (
+	ImportCreated     ImportAction = "imported"
+	ImportRenamed     ImportAction = "renamed"
+	ImportSkipped     ImportAction = "skipped"
+	ImportOverwritten ImportAction = "overwritten"
+	ImportFailed      ImportAction = "failed"
+)
+
+// ImportResult reports what happened to a single imported file
+type ImportResult struct {
+	Path   string
+	Name   string
+	Action ImportAction
+	Err    error
+}
+
+// Import copies the file at path into the storage as a note named after the file,
+// resolving name collisions with the given policy. A failed auto-commit is
+// reported in Err without failing the import.
+func (s *Store) Import(path string, policy CollisionPolicy) ImportResult {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	result := ImportResult{Path: path, Name: name}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		result.Action = ImportFailed
+		result.Err = fmt.Errorf("failed to read %s: %w", path, err)
+		return result
+	}
+
+	result.Action = ImportCreated
+
+	if s.noteExists(name) {
+		switch policy {
+		case CollisionSkip:
+			result.Action = ImportSkipped
+			return result
+
+		case CollisionOverwrite:
+			result.Action = ImportOverwritten
+
+		default:
+			result.Name = s.generateUniqueName(name)
+			result.Action = ImportRenamed
+		}
+	}
+
+	note := Note{
+		Name:      result.Name,
+		Content:   s.expandTabs(strings.Trim(string(data), "\n")),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.saveNote(note.Name, note); err != nil {
+		result.Action = ImportFailed
+		result.Err = err
+		return result
+	}
+
+	if _, exists := s.notesDictionary[noteKey(note.Name)]; !exists {
+		s.notes = append(s.notes, note)
+	}
+
+	s.notesDictionary[noteKey(note.Name)] = note
+
+	if result.Action == ImportOverwritten {
+		result.Err = s.commit("update " + note.Name)
+	} else {
+		result.Err = s.commit("create " + note.Name)
+	}
+
+	return result
+}
+
+func (s Store) noteExists(name string) bool {
+	if _, exists := s.notesDictionary[noteKey(name)]; exists {
+		return true
+	}
+
+	_, err := os.Stat(s.GetNotePath(name))
+
+	return err == nil
+}
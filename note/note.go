@@ -1,11 +1,15 @@
 package note
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"io/fs"
+	"math/rand/v2"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
@@ -14,6 +18,7 @@ import (
 	"github.com/atotto/clipboard"
 	"github.com/ionut-t/notes/internal/config"
 	"github.com/ionut-t/notes/internal/utils"
+	"github.com/ionut-t/notes/markdown"
 )
 
 type configService interface {
@@ -34,6 +39,24 @@ func (c configServiceImpl) SetEditor(editor string) error {
 	return config.SetEditor(editor)
 }
 
+// staticConfig keeps the storage and the editor of a store created with
+// NewStoreWithConfig, leaving the config file untouched
+type staticConfig struct {
+	storage string
+	editor  string
+}
+
+func (c *staticConfig) GetStorage() string {
+	return c.storage
+}
+func (c *staticConfig) GetEditor() string {
+	return c.editor
+}
+func (c *staticConfig) SetEditor(editor string) error {
+	c.editor = editor
+	return nil
+}
+
 type clipboardService interface {
 	copy(text string) error
 }
@@ -45,21 +68,112 @@ func (c clipboardServiceImpl) copy(text string) error {
 }
 
 type Note struct {
-	Name      string
-	Content   string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Byte      []byte
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Content     string    `json:"content"`
+	Aliases     []string  `json:"aliases,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Folder      string    `json:"folder,omitempty"`
+	Pinned      bool      `json:"pinned,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Byte        []byte    `json:"-"`
+}
+
+// Title returns the title set in the frontmatter of the note,
+// falling back to its name
+func (n Note) Title() string {
+	return cmp.Or(n.DisplayName, n.Name)
+}
+
+// HasTag reports whether the note is tagged with the given tag, ignoring case
+func (n Note) HasTag(tag string) bool {
+	return slices.ContainsFunc(n.Tags, func(t string) bool {
+		return strings.EqualFold(t, tag)
+	})
+}
+
+var nameSeparatorsRegex = regexp.MustCompile(`[#\s-]+`)
+
+// NameFromHeader derives a note name from the header on the first line
+// of the content, lower case with dashes between words: "# My Note" is
+// named "my-note"
+func NameFromHeader(content string) (string, bool) {
+	line := strings.Split(content, "\n")[0]
+
+	if !strings.HasPrefix(line, "#") {
+		return "", false
+	}
+
+	name := nameSeparatorsRegex.ReplaceAllString(strings.TrimSpace(strings.Trim(line, "#")), " ")
+	name = strings.ToLower(strings.Join(strings.Fields(name), "-"))
+
+	return name, name != ""
+}
+
+// titlePlaceholder stands for the name derived from the header of the note in a name template
+const titlePlaceholder = "{title}"
+
+// ExpandNameTemplate names a note after a template such as "2006-01-02-{title}",
+// formatting the time with the Go layout around the {title} placeholder, which is
+// replaced with the title. The separators left at either end by an empty title
+// are trimmed, so "2006-01-02-{title}" names untitled notes after the date.
+func ExpandNameTemplate(template, title string, now time.Time) string {
+	parts := strings.Split(template, titlePlaceholder)
+
+	for i, part := range parts {
+		parts[i] = now.Format(part)
+	}
+
+	return strings.Trim(strings.Join(parts, title), "-_ ")
 }
 
+// WordCount returns the number of whitespace separated words in the content
+func WordCount(content string) int {
+	return len(strings.Fields(content))
+}
+
+// wordsPerMinute is the reading speed ReadingTime assumes
+const wordsPerMinute = 200
+
+// ReadingTime estimates the minutes it takes to read the given
+// number of words, rounded up
+func ReadingTime(words int) int {
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// ScratchNote is the name of the scratchpad, always reachable with
+// a binding of the manager and never renamed
+const ScratchNote = "scratch"
+
 type Store struct {
 	storage          string
+	extension        string // Extension of the note files, with its dot
 	editor           string
 	notes            []Note
 	notesDictionary  map[string]Note
+	aliases          map[string]string
 	currentNoteName  string
 	configService    configService
 	clipboardService clipboardService
+	gitAutoCommit    bool
+	// tabsToSpaces replaces the tabs of the created and saved notes
+	// with spaces up to the tab stops, every tabWidth columns
+	tabsToSpaces bool
+	tabWidth     int
+
+	// key the notes are encrypted with once unlocked, nil when they are saved as plaintext
+	key []byte
+
+	// trashed holds where the notes deleted since the store was created are
+	// kept in the trash, by the key of their name, for Undelete
+	trashed map[string]trashedNote
+}
+
+// trashedNote is a deleted note as it was moved into the trash
+type trashedNote struct {
+	name   string // Name the note is kept under in the trash
+	pinned bool
 }
 
 func NewStore() *Store {
@@ -67,49 +181,170 @@ func NewStore() *Store {
 	storage := configService.GetStorage()
 	editor := configService.GetEditor()
 
+	extension, err := config.GetExtension()
+	if err != nil {
+		extension = config.DefaultExtension
+	}
+
 	store := &Store{
 		storage:          storage,
+		extension:        extension,
 		editor:           editor,
 		notesDictionary:  make(map[string]Note),
 		configService:    configService,
 		clipboardService: clipboardServiceImpl{},
+		gitAutoCommit:    config.GetGitAutoCommit(),
+		tabsToSpaces:     config.GetTabsToSpaces(),
+		tabWidth:         config.GetTabWidth(),
 	}
 
 	return store
 }
 
+// NewStoreWithConfig creates a store of the notes in the storage directory,
+// opened with the given external editor, without reading or writing the
+// config file. It's meant for using the notes from other Go programs,
+// which call LoadNotes before anything else. The notes keep the default
+// extension, and are neither encrypted nor committed to git.
+func NewStoreWithConfig(storage, editor string) *Store {
+	return &Store{
+		storage:          storage,
+		extension:        config.DefaultExtension,
+		editor:           editor,
+		notesDictionary:  make(map[string]Note),
+		configService:    &staticConfig{storage: storage, editor: editor},
+		clipboardService: clipboardServiceImpl{},
+	}
+}
+
 func (s Store) GetNotes() []Note {
 	return s.notes
 }
 
 func (s *Store) GetCurrentNote() (Note, bool) {
-	if note, ok := s.notesDictionary[s.currentNoteName]; ok {
-		return note, true
+	if name, ok := s.ResolveName(s.currentNoteName); ok {
+		return s.notesDictionary[noteKey(name)], true
 	}
 
 	return Note{}, false
 }
 
+// ResolveName returns the name of the note matching the given name or alias
+func (s *Store) ResolveName(nameOrAlias string) (string, bool) {
+	if note, ok := s.notesDictionary[noteKey(nameOrAlias)]; ok {
+		return note.Name, true
+	}
+
+	name, ok := s.aliases[strings.ToLower(nameOrAlias)]
+
+	return name, ok
+}
+
+// indexAliases maps every alias to its note. Aliases clashing with a note name
+// or declared by more than one note are rejected and don't resolve
+func (s *Store) indexAliases() {
+	aliases := make(map[string]string)
+	rejected := make(map[string]bool)
+
+	for key, note := range s.notesDictionary {
+		for _, alias := range note.Aliases {
+			alias = strings.ToLower(strings.TrimSpace(alias))
+
+			if _, isName := s.notesDictionary[alias]; alias == "" || isName || rejected[alias] {
+				continue
+			}
+
+			if owner, exists := aliases[alias]; exists && noteKey(owner) != key {
+				delete(aliases, alias)
+				rejected[alias] = true
+				continue
+			}
+
+			aliases[alias] = note.Name
+		}
+	}
+
+	s.aliases = aliases
+}
+
 func (s *Store) SetCurrentNoteName(name string) {
+	if resolved, ok := s.ResolveName(name); ok {
+		name = resolved
+	}
+
 	s.currentNoteName = name
 }
 
 func (s *Store) Create(name, content string) error {
 	note := Note{
 		Name:      name,
-		Content:   content,
+		Content:   s.expandTabs(content),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	uniqueName := s.generateUniqueName(note.Name)
-	err := s.saveNote(uniqueName, note)
 
-	if err == nil {
-		s.currentNoteName = uniqueName
+	if err := s.saveNote(uniqueName, note); err != nil {
+		return err
 	}
 
-	return err
+	s.currentNoteName = uniqueName
+
+	return s.commit("create " + uniqueName)
+}
+
+// ExtractLines creates a note with the lines start to end of the current note,
+// counted from 1 and inclusive, and returns the name it was created with
+func (s *Store) ExtractLines(start, end int, name string) (string, error) {
+	current, ok := s.GetCurrentNote()
+	if !ok {
+		return "", errors.New("note not found")
+	}
+
+	lines := strings.Split(current.Content, "\n")
+	if start < 1 || end < start || end > len(lines) {
+		return "", fmt.Errorf("invalid line range %d-%d, note %s has %d lines", start, end, current.Name, len(lines))
+	}
+
+	err := s.Create(name, strings.Join(lines[start-1:end], "\n"))
+	if err != nil && !errors.Is(err, ErrAutoCommit) {
+		return "", err
+	}
+
+	return s.currentNoteName, err
+}
+
+// Duplicate writes a copy of the note named after it with a "-copy" suffix
+// and makes the copy the current note. The copy is also returned along with
+// an ErrAutoCommit error, since it was created.
+func (s *Store) Duplicate(name string) (Note, error) {
+	source, ok := s.notesDictionary[noteKey(name)]
+	if !ok {
+		return Note{}, errors.New("note not found")
+	}
+
+	now := time.Now()
+
+	duplicate := Note{
+		Name:        s.generateUniqueName(name + "-copy"),
+		DisplayName: source.DisplayName,
+		Content:     s.expandTabs(source.Content),
+		Tags:        source.Tags,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.saveNote(duplicate.Name, duplicate); err != nil {
+		return Note{}, err
+	}
+
+	s.notesDictionary[noteKey(duplicate.Name)] = duplicate
+	s.notes = append([]Note{duplicate}, s.notes...)
+	s.indexAliases()
+	s.currentNoteName = duplicate.Name
+
+	return duplicate, s.commit("create " + duplicate.Name)
 }
 
 func (s *Store) DeleteCurrentNote() error {
@@ -120,132 +355,493 @@ func (s *Store) DeleteCurrentNote() error {
 	return errors.New("note not found")
 }
 
+// Delete moves the note into the trash directory, from where it can be restored
 func (s *Store) Delete(name string) error {
-	path := filepath.Join(s.storage, name+".md")
+	path := s.GetNotePath(name)
 
-	if err := os.Remove(path); err != nil {
+	trashName, err := s.moveToTrash(path)
+	if err != nil {
 		return fmt.Errorf("failed to delete note file: %w", err)
 	}
 
+	if s.trashed == nil {
+		s.trashed = make(map[string]trashedNote)
+	}
+
+	s.trashed[noteKey(name)] = trashedNote{name: trashName, pinned: s.loadPinned()[noteKey(name)]}
+
 	notes := slices.DeleteFunc(s.notes, func(n Note) bool {
 		return n.Name == name
 	})
 
 	s.notes = notes
+	delete(s.notesDictionary, noteKey(name))
+	s.indexAliases()
 
-	return nil
+	if err := s.removePin(name); err != nil {
+		return err
+	}
+
+	if err := s.DeleteDraft(name); err != nil {
+		return fmt.Errorf("failed to delete the draft: %w", err)
+	}
+
+	return s.commit("delete " + name)
 }
 
 func (s *Store) UpdateCurrentNoteContent(newContent string) error {
 	if note, ok := s.GetCurrentNote(); ok {
-		note.Content = newContent
-		note.UpdatedAt = time.Now()
+		return s.updateNoteContent(note, newContent)
+	}
 
-		err := s.saveNote(note.Name, note)
+	return errors.New("note not found")
+}
 
-		if err != nil {
-			return err
+// expandTabs replaces the tabs of the content with spaces
+// when tabs_to_spaces is enabled
+func (s *Store) expandTabs(content string) string {
+	if !s.tabsToSpaces {
+		return content
+	}
+
+	return utils.ExpandTabs(content, s.tabWidth)
+}
+
+// updateNoteContent saves the new content of the note, moving it
+// to the top of the list as the most recently modified note
+func (s *Store) updateNoteContent(note Note, newContent string) error {
+	newContent = s.expandTabs(newContent)
+	note.Content = newContent
+	fm := parseFrontmatter(newContent)
+	note.DisplayName = strings.TrimSpace(fm.Title)
+	note.Aliases = fm.Aliases
+	note.Tags = fm.Tags
+	note.UpdatedAt = time.Now()
+
+	err := s.saveNote(note.Name, note)
+
+	if err != nil {
+		return err
+	}
+
+	s.notesDictionary[noteKey(note.Name)] = note
+	s.indexAliases()
+
+	s.notes = slices.DeleteFunc(s.notes, func(n Note) bool {
+		return n.Name == note.Name
+	})
+
+	s.notes = append([]Note{note}, s.notes...)
+
+	if err := s.DeleteDraft(note.Name); err != nil {
+		return err
+	}
+
+	return s.commit("update " + note.Name)
+}
+
+// Append adds text as a new line at the end of the current note
+func (s *Store) Append(text string) error {
+	note, ok := s.GetCurrentNote()
+	if !ok {
+		return errors.New("note not found")
+	}
+
+	content := note.Content
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	return s.UpdateCurrentNoteContent(content + text)
+}
+
+// Merge appends the content of the source note to the target note, under
+// a separator and a header with the source name, then deletes the source
+func (s *Store) Merge(target, source string) error {
+	if noteKey(target) == noteKey(source) {
+		return errors.New("cannot merge a note into itself")
+	}
+
+	targetNote, ok := s.notesDictionary[noteKey(target)]
+	if !ok {
+		return fmt.Errorf("note %q not found", target)
+	}
+
+	sourceNote, ok := s.notesDictionary[noteKey(source)]
+	if !ok {
+		return fmt.Errorf("note %q not found", source)
+	}
+
+	content := targetNote.Content
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	targetNote.Content = content + "\n---\n\n# " + sourceNote.Name + "\n\n" + sourceNote.Content
+	targetNote.UpdatedAt = time.Now()
+
+	if err := s.saveNote(targetNote.Name, targetNote); err != nil {
+		return err
+	}
+
+	s.notesDictionary[noteKey(targetNote.Name)] = targetNote
+	s.notes = slices.DeleteFunc(s.notes, func(n Note) bool {
+		return n.Name == targetNote.Name
+	})
+	s.notes = append([]Note{targetNote}, s.notes...)
+
+	err := s.Delete(sourceNote.Name)
+	if err != nil && !errors.Is(err, ErrAutoCommit) {
+		return err
+	}
+
+	s.indexAliases()
+	s.currentNoteName = targetNote.Name
+
+	return err
+}
+
+// Random returns a randomly picked note, if there are any
+func (s Store) Random() (Note, bool) {
+	if len(s.notes) == 0 {
+		return Note{}, false
+	}
+
+	return s.notes[rand.IntN(len(s.notes))], true
+}
+
+// CopyNotes copies the content of the named notes to the clipboard,
+// each one under a header with its name
+func (s Store) CopyNotes(names []string) error {
+	sections := make([]string, len(names))
+
+	for i, nameOrAlias := range names {
+		name, ok := s.ResolveName(nameOrAlias)
+		if !ok {
+			return fmt.Errorf("note %s not found", nameOrAlias)
 		}
 
-		s.notesDictionary[note.Name] = note
+		sections[i] = "# " + name + "\n\n" + s.notesDictionary[noteKey(name)].Content
+	}
 
-		s.notes = slices.DeleteFunc(s.notes, func(n Note) bool {
-			return n.Name == note.Name
-		})
+	return s.clipboardService.copy(strings.Join(sections, "\n\n"))
+}
 
-		s.notes = append([]Note{note}, s.notes...)
+// CopyCodeBlock copies the code of the nth code block of the current note,
+// counted from 1, to the clipboard and returns the block
+func (s *Store) CopyCodeBlock(n int) (markdown.CodeBlock, error) {
+	current, ok := s.GetCurrentNote()
+	if !ok {
+		return markdown.CodeBlock{}, errors.New("note not found")
+	}
 
-		return nil
+	blocks := markdown.New(current.Content, 0).CodeBlocks()
+	if n < 1 || n > len(blocks) {
+		return markdown.CodeBlock{}, fmt.Errorf("note %s has %d code blocks, no block %d", current.Name, len(blocks), n)
 	}
 
-	return errors.New("note not found")
+	block := blocks[n-1]
+
+	return block, s.clipboardService.copy(block.Code)
 }
 
-func (s *Store) RenameCurrentNote(newName string) (Note, error) {
-	if note, ok := s.GetCurrentNote(); ok {
-		if renamedNote, err := s.RenameNote(note.Name, newName); err == nil {
-			s.SetCurrentNoteName(renamedNote.Name)
-			return renamedNote, nil
+// CopyPlainText copies the content to the clipboard without its
+// frontmatter and markdown syntax, for pasting where it isn't rendered
+func (s Store) CopyPlainText(content string) error {
+	body := Note{Content: content}.Body()
+	return s.clipboardService.copy(markdown.New(body, 0).PlainText())
+}
+
+// GetExternalChanges returns the content currently on disk for the given note
+// when it differs from the version loaded in the store
+func (s *Store) GetExternalChanges(name string) (string, bool) {
+	note, ok := s.notesDictionary[noteKey(name)]
+	if !ok {
+		return "", false
+	}
+
+	data, err := s.readNote(s.GetNotePath(name))
+	if err != nil {
+		return "", false
+	}
+
+	diskContent := strings.Trim(string(data), "\n")
+
+	if diskContent == strings.Trim(note.Content, "\n") {
+		return "", false
+	}
+
+	return diskContent, true
+}
+
+// ReloadCurrentNote reads the current note back from disk,
+// replacing the version held in memory
+func (s *Store) ReloadCurrentNote() (Note, error) {
+	current, ok := s.GetCurrentNote()
+	if !ok {
+		return Note{}, errors.New("note not found")
+	}
+
+	note, err := s.loadNoteFromFile(s.GetNotePath(current.Name))
+	if err != nil {
+		return Note{}, err
+	}
+
+	s.notesDictionary[noteKey(note.Name)] = note
+
+	for i, n := range s.notes {
+		if n.Name == note.Name {
+			s.notes[i] = note
+			break
 		}
 	}
 
-	return Note{}, errors.New("note not found")
+	return note, nil
+}
+
+func (s *Store) RenameCurrentNote(newName string) (Note, error) {
+	note, ok := s.GetCurrentNote()
+	if !ok {
+		return Note{}, errors.New("note not found")
+	}
+
+	renamedNote, err := s.RenameNote(note.Name, newName)
+	if err != nil && !errors.Is(err, ErrAutoCommit) {
+		return Note{}, err
+	}
+
+	s.SetCurrentNoteName(renamedNote.Name)
+
+	return renamedNote, err
 }
 
-func (s Store) RenameNote(currentName, newName string) (Note, error) {
+func (s *Store) RenameNote(currentName, newName string) (Note, error) {
+	if noteKey(currentName) == noteKey(ScratchNote) && noteKey(newName) != noteKey(ScratchNote) {
+		return Note{}, fmt.Errorf("the %s note can't be renamed", ScratchNote)
+	}
+
 	currentPath := s.GetNotePath(currentName)
 
-	newName = s.generateUniqueName(newName)
+	// changing only the case of the name can't collide with another note
+	if noteKey(newName) != noteKey(currentName) {
+		newName = s.generateUniqueName(newName)
+	}
+
+	if err := checkNoteName(newName); err != nil {
+		return Note{}, err
+	}
 
 	newPath := s.GetNotePath(newName)
 
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return Note{}, fmt.Errorf("failed to create notes directory: %w", err)
+	}
+
 	if err := os.Rename(currentPath, newPath); err != nil {
 		return Note{}, fmt.Errorf("failed to rename note file: %w", err)
 	}
 
+	if err := s.movePin(currentName, newName); err != nil {
+		return Note{}, err
+	}
+
+	if err := s.moveDraft(currentName, newName); err != nil {
+		return Note{}, fmt.Errorf("failed to move the draft: %w", err)
+	}
+
 	for i, note := range s.notes {
 		if note.Name == currentName {
 			s.notes[i].Name = newName
-			delete(s.notesDictionary, currentName)
-			s.notesDictionary[newName] = s.notes[i]
+			delete(s.notesDictionary, noteKey(currentName))
+			s.notesDictionary[noteKey(newName)] = s.notes[i]
+			s.indexAliases()
 			s.currentNoteName = newName
-			return s.notes[i], nil
+			return s.notes[i], s.commit(fmt.Sprintf("rename %s to %s", currentName, newName))
 		}
 	}
 
 	return Note{}, nil
 }
 
-func (s *Store) LoadNotes() ([]Note, error) {
-	notes := []Note{}
+// Rename is a note renamed by RenameAll
+type Rename struct {
+	From string
+	To   string
+}
 
-	err := filepath.WalkDir(s.storage, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			if os.IsNotExist(err) {
-				return nil
+// RenameAll replaces find with replace in the name of every note, adding
+// a counter to the names that collide like RenameNote does. With dryRun the
+// renames are only worked out and returned. Names left blank are skipped.
+func (s *Store) RenameAll(find, replace string, dryRun bool) ([]Rename, error) {
+	if find == "" {
+		return nil, errors.New("the text to find cannot be empty")
+	}
+
+	if strings.ContainsAny(replace, `/\`) {
+		return nil, errors.New("the replacement cannot contain path separators")
+	}
+
+	names := make([]string, 0, len(s.notes))
+	taken := make(map[string]bool, len(s.notes))
+
+	for _, n := range s.notes {
+		names = append(names, n.Name)
+		taken[noteKey(n.Name)] = true
+	}
+
+	slices.Sort(names)
+
+	var renames []Rename
+	var commitErr error
+
+	for _, name := range names {
+		newName := strings.ReplaceAll(name, find, replace)
+		if newName == name || strings.TrimSpace(newName) == "" || noteKey(name) == noteKey(ScratchNote) {
+			continue
+		}
+
+		if dryRun {
+			delete(taken, noteKey(name))
+
+			if noteKey(newName) != noteKey(name) {
+				newName = uniqueName(newName, func(name string) bool { return taken[noteKey(name)] })
 			}
 
-			return err
+			taken[noteKey(newName)] = true
+			renames = append(renames, Rename{From: name, To: newName})
+			continue
 		}
 
-		// Skip directories and non-markdown files
-		if d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
-			return nil
+		renamed, err := s.RenameNote(name, newName)
+		if err != nil && !errors.Is(err, ErrAutoCommit) {
+			return renames, err
 		}
 
+		if s.currentNoteName == name {
+			s.currentNoteName = renamed.Name
+		}
+
+		commitErr = cmp.Or(commitErr, err)
+		renames = append(renames, Rename{From: name, To: renamed.Name})
+	}
+
+	return renames, commitErr
+}
+
+// SwitchStorage loads the notes of another storage in place of the current
+// ones. The store is left on the current storage when they fail to load.
+func (s *Store) SwitchStorage(storage string) ([]Note, error) {
+	previousStorage, previousNote := s.storage, s.currentNoteName
+
+	s.storage = storage
+	s.currentNoteName = ""
+
+	notes, err := s.LoadNotes()
+	if err != nil {
+		s.storage, s.currentNoteName = previousStorage, previousNote
+		return nil, err
+	}
+
+	s.trashed = nil
+
+	return notes, nil
+}
+
+// LoadNotes reads every note from the storage, replacing the notes
+// loaded before so that notes deleted since are dropped
+func (s *Store) LoadNotes() ([]Note, error) {
+	notes := []Note{}
+	dictionary := make(map[string]Note)
+	pinned := s.loadPinned()
+
+	err := s.walkNoteFiles(func(path string) error {
 		note, err := s.loadNoteFromFile(path)
 		if err != nil {
 			return fmt.Errorf("error loading note %s: %w", path, err)
 		}
 
+		note.Pinned = pinned[noteKey(note.Name)]
+
 		notes = append(notes, note)
-		s.notesDictionary[note.Name] = note
+		dictionary[noteKey(note.Name)] = note
 		return nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("error walking notes directory: %w", err)
+		return nil, err
 	}
 
-	slices.SortStableFunc(notes, func(i, j Note) int {
-		if i.UpdatedAt.After(j.UpdatedAt) {
-			return -1
+	slices.SortStableFunc(notes, compareByUpdatedAt)
+
+	s.notes = notes
+	s.notesDictionary = dictionary
+	s.indexAliases()
+
+	if len(notes) > 0 {
+		s.currentNoteName = utils.Ternary(s.currentNoteName == "", notes[0].Name, s.currentNoteName)
+	}
+
+	return notes, nil
+}
+
+// Names returns the name of every note without reading their content,
+// sorted alphabetically. It is meant for cheap lookups such as shell completion.
+func (s Store) Names() ([]string, error) {
+	var names []string
+
+	err := s.walkNoteFiles(func(path string) error {
+		names = append(names, s.nameOf(path))
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// walkNoteFiles calls fn with the path of every markdown file in the storage,
+// skipping hidden directories such as templates
+func (s Store) walkNoteFiles(fn func(path string) error) error {
+	err := filepath.WalkDir(s.storage, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
 		}
 
-		if i.UpdatedAt.Before(j.UpdatedAt) {
-			return 1
+		if d.IsDir() && path != s.storage && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		// Skip directories and non-markdown files
+		if d.IsDir() || !strings.HasSuffix(d.Name(), s.extension) {
+			return nil
 		}
 
-		return 0
+		return fn(path)
 	})
 
-	s.notes = notes
+	if err != nil {
+		return fmt.Errorf("error walking notes directory: %w", err)
+	}
 
-	if len(notes) > 0 {
-		s.currentNoteName = utils.Ternary(s.currentNoteName == "", notes[0].Name, s.currentNoteName)
+	return nil
+}
+
+// compareByUpdatedAt orders notes from the most recently updated,
+// falling back to the name so that ties keep a stable order across reloads
+func compareByUpdatedAt(i, j Note) int {
+	if c := j.UpdatedAt.Compare(i.UpdatedAt); c != 0 {
+		return c
 	}
 
-	return notes, nil
+	return strings.Compare(i.Name, j.Name)
 }
 
 // used to determine if the note was updated externally
@@ -259,9 +855,25 @@ func (s *Store) IsFirstNote() bool {
 }
 
 func (s Store) GetEditor() string {
+	if s.editor != "" {
+		return s.editor
+	}
+
 	return s.configService.GetEditor()
 }
 
+// UseEditorOnce switches the external editor until the notes are
+// closed, leaving the configured editor unchanged
+func (s *Store) UseEditorOnce(editor string) error {
+	if _, err := exec.LookPath(editor); err != nil {
+		return fmt.Errorf("editor %q not found", editor)
+	}
+
+	s.editor = editor
+
+	return nil
+}
+
 func (s *Store) SetEditor(editor string) error {
 	err := s.configService.SetEditor(editor)
 
@@ -273,40 +885,121 @@ func (s *Store) SetEditor(editor string) error {
 	return nil
 }
 
+// GetNotePath returns the file of the note. The folders of a name
+// such as "work/todo" are subdirectories of the storage.
 func (s Store) GetNotePath(name string) string {
-	return filepath.Join(s.storage, name+".md")
+	return filepath.Join(s.storage, name+s.extension)
+}
+
+// nameOf returns the name of the note stored in the file,
+// its path relative to the storage with forward slashes
+func (s Store) nameOf(path string) string {
+	rel, err := filepath.Rel(s.storage, path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+
+	return strings.TrimSuffix(filepath.ToSlash(rel), s.extension)
+}
+
+// checkNoteName rejects the names whose folders would leave the storage
+// or be hidden, as the notes in hidden directories aren't loaded
+func checkNoteName(name string) error {
+	folders := strings.Split(filepath.ToSlash(name), "/")
+
+	for i, part := range folders {
+		if part == "" || part == "." || part == ".." || (i < len(folders)-1 && strings.HasPrefix(part, ".")) {
+			return fmt.Errorf("invalid note name %q", name)
+		}
+	}
+
+	return nil
 }
 
 // saveNote saves a note to the store
 func (s *Store) saveNote(name string, note Note) error {
-	path := filepath.Join(s.storage, name+".md")
+	if err := checkNoteName(name); err != nil {
+		return err
+	}
+
+	path := s.GetNotePath(name)
 
 	// Create the note content
 	content := strings.Trim(note.Content, "\n")
 
-	// check if directory exists
-	if _, err := os.Stat(s.storage); os.IsNotExist(err) {
-		if err := os.MkdirAll(s.storage, 0755); err != nil {
-			return fmt.Errorf("failed to create notes directory: %w", err)
-		}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create notes directory: %w", err)
+	}
+
+	data, err := s.sealNote(content)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt note: %w", err)
 	}
 
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+	if err := writeFileAtomic(path, data); err != nil {
 		return fmt.Errorf("failed to write note file: %w", err)
 	}
 
 	return nil
 }
 
+// writeFileAtomic writes the data to a temporary file next to the target
+// and renames it into place, so an existing file is never left half written
+func writeFileAtomic(path string, data []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := tmpFile.Name()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
+// noteKey is the key of a note in the dictionary. Names differing only by
+// case share a key, as they would the same file on case-insensitive filesystems.
+func noteKey(name string) string {
+	return strings.ToLower(name)
+}
+
 func (s Store) generateUniqueName(name string) string {
+	return uniqueName(name, func(name string) bool {
+		if _, exists := s.notesDictionary[noteKey(name)]; exists {
+			return true
+		}
+
+		_, err := os.Stat(s.GetNotePath(name))
+		return err == nil
+	})
+}
+
+// uniqueName appends a counter to name until exists reports it is free
+func uniqueName(name string, exists func(name string) bool) string {
 	originalName := name
 	counter := 1
 
-	for {
-		if _, exists := s.notesDictionary[strings.ToLower(name)]; !exists {
-			break
-		}
-
+	for exists(name) {
 		name = originalName + "-" + strconv.Itoa(counter)
 		counter++
 	}
@@ -314,40 +1007,20 @@ func (s Store) generateUniqueName(name string) string {
 	return name
 }
 
-// func getCreationTime(filePath string) (time.Time, error) {
-// 	info, err := os.Stat(filePath)
-// 	if err != nil {
-// 		return time.Time{}, err
-// 	}
-
-// 	stat, ok := info.Sys().(*syscall.Stat_t)
-// 	if !ok {
-// 		return time.Time{}, fmt.Errorf("failed to get raw syscall.Stat_t")
-// 	}
-
-// 	// Different OS has different fields in syscall.Stat_t
-// 	switch runtime.GOOS {
-// 	case "darwin":
-// 		// macOS has Birthtimespec for creation time
-// 		return time.Unix(int64(stat.Birthtimespec.Sec), int64(stat.Birthtimespec.Nsec)), nil
-// 	case "windows":
-// 		// Windows implementation would be different and should use syscall.GetFileTime
-// 		return time.Time{}, fmt.Errorf("use separate Windows implementation")
-// 	default:
-// 		// Linux generally doesn't store true creation time, using Ctim (status change time)
-// 		return time.Unix(int64(stat.Ctimespec.Sec), int64(stat.Ctimespec.Nsec)), nil
-// 	}
-// }
-
 func (s *Store) loadNoteFromFile(path string) (Note, error) {
-	data, err := os.ReadFile(path)
+	data, err := s.readNote(path)
 	if err != nil {
 		return Note{}, fmt.Errorf("failed to read note file: %w", err)
 	}
 
 	content := strings.TrimSuffix(string(data), "\n")
 
-	name := strings.TrimSuffix(filepath.Base(path), ".md")
+	name := s.nameOf(path)
+
+	folder, err := filepath.Rel(s.storage, filepath.Dir(path))
+	if err != nil || folder == "." {
+		folder = ""
+	}
 
 	fileInfo, err := os.Stat(path)
 
@@ -356,21 +1029,20 @@ func (s *Store) loadNoteFromFile(path string) (Note, error) {
 		return Note{}, err
 	}
 
-	// Creation time is not supported on all platforms
-	// TODO: Investigate if we can get the creation time on all platforms
-	// createdAt, err := getCreationTime(path)
-
-	// if err != nil {
-	// 	fmt.Printf("Error getting file creation time: %v\n", err)
-	// 	return Note{}, err
-	// }
-
+	createdAt := creationTime(fileInfo)
 	updatedAt := fileInfo.ModTime()
 
+	fm := parseFrontmatter(content)
+
 	return Note{
-		Name:      name,
-		Content:   content,
-		UpdatedAt: updatedAt,
-		Byte:      data,
+		Name:        name,
+		DisplayName: strings.TrimSpace(fm.Title),
+		Content:     content,
+		Aliases:     fm.Aliases,
+		Tags:        fm.Tags,
+		Folder:      filepath.ToSlash(folder),
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		Byte:        data,
 	}, nil
 }
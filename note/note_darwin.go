@@ -0,0 +1,17 @@
+package note
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// creationTime reads the birth time macOS keeps for every file
+func creationTime(info os.FileInfo) time.Time {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.ModTime()
+	}
+
+	return time.Unix(stat.Birthtimespec.Sec, stat.Birthtimespec.Nsec)
+}
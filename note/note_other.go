@@ -0,0 +1,14 @@
+//go:build !darwin && !windows
+
+package note
+
+import (
+	"os"
+	"time"
+)
+
+// creationTime falls back to the modification time, as Linux and most
+// other systems don't expose a birth time through os.Stat
+func creationTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
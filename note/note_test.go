@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -59,6 +61,7 @@ func setupTestStore(t *testing.T) *Store {
 
 	store := &Store{
 		storage:          tempDir,
+		extension:        ".md",
 		editor:           mockConfig.editor,
 		notesDictionary:  make(map[string]Note),
 		configService:    mockConfig,
@@ -78,6 +81,18 @@ func TestStore_SetEditor(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestStore_UseEditorOnce(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.UseEditorOnce("sh"))
+	assert.Equal(t, "sh", store.GetEditor())
+	assert.Equal(t, "vim", store.configService.GetEditor())
+
+	assert.Error(t, store.UseEditorOnce("no-such-editor-anywhere"))
+	assert.Equal(t, "sh", store.GetEditor())
+}
+
 func TestStore_loadNoteFromFile(t *testing.T) {
 	t.Parallel()
 
@@ -148,6 +163,7 @@ func TestStore_saveNote_DirectoryCreation(t *testing.T) {
 
 	store := &Store{
 		storage:         tempDir,
+		extension:       ".md",
 		editor:          "vim",
 		notesDictionary: make(map[string]Note),
 	}
@@ -417,3 +433,1538 @@ func TestStore_IsFirstNote(t *testing.T) {
 	store.SetCurrentNoteName("first-note")
 	assert.False(t, store.IsFirstNote(), "Should not be the first note when an older one is selected") //
 }
+
+func TestStore_GetExternalChanges(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	err := store.Create("external", "original content")
+	assert.NoError(t, err)
+
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+
+	_, changed := store.GetExternalChanges("external")
+	assert.False(t, changed, "Freshly loaded note should not report external changes")
+
+	err = os.WriteFile(store.GetNotePath("external"), []byte("changed on disk\n"), 0644)
+	assert.NoError(t, err)
+
+	diskContent, changed := store.GetExternalChanges("external")
+	assert.True(t, changed)
+	assert.Equal(t, "changed on disk", diskContent)
+
+	note, err := store.ReloadCurrentNote()
+	assert.NoError(t, err)
+	assert.Equal(t, "changed on disk", note.Content)
+
+	_, changed = store.GetExternalChanges("external")
+	assert.False(t, changed, "Reloaded note should match the disk content")
+}
+
+func TestWordDiff(t *testing.T) {
+	t.Parallel()
+
+	ops := WordDiff("the quick brown fox", "the slow brown fox jumps")
+
+	var oldText, newText string
+	for _, op := range ops {
+		if op.Kind != DiffInsert {
+			oldText += op.Text
+		}
+		if op.Kind != DiffDelete {
+			newText += op.Text
+		}
+	}
+
+	assert.Equal(t, "the quick brown fox", oldText)
+	assert.Equal(t, "the slow brown fox jumps", newText)
+	assert.Contains(t, ops, DiffOp{Kind: DiffDelete, Text: "quick"})
+	assert.Contains(t, ops, DiffOp{Kind: DiffInsert, Text: "slow"})
+}
+
+func TestLineDiff(t *testing.T) {
+	t.Parallel()
+
+	ops := LineDiff("a\nb\nc\nd", "a\nB\nc\nd\ne\nf\n")
+
+	assert.Equal(t, []DiffOp{
+		{Kind: DiffEqual, Text: "a\n"},
+		{Kind: DiffDelete, Text: "b\n"},
+		{Kind: DiffInsert, Text: "B\n"},
+		{Kind: DiffEqual, Text: "c\nd\n"},
+		{Kind: DiffInsert, Text: "e\nf\n"},
+	}, ops)
+
+	inserted, deleted := LineChanges(ops)
+	assert.Equal(t, 3, inserted)
+	assert.Equal(t, 1, deleted)
+
+	assert.Empty(t, LineDiff("", ""))
+}
+
+func TestWordDiff_LargeText(t *testing.T) {
+	t.Parallel()
+
+	var oldText, newText strings.Builder
+	for i := range 20000 {
+		fmt.Fprintf(&oldText, "old%d ", i)
+		fmt.Fprintf(&newText, "new%d ", i)
+	}
+
+	ops := WordDiff("same start\n"+oldText.String()+"\nsame end", "same start\n"+newText.String()+"\nsame end")
+
+	var gotOld, gotNew string
+	for _, op := range ops {
+		if op.Kind != DiffInsert {
+			gotOld += op.Text
+		}
+		if op.Kind != DiffDelete {
+			gotNew += op.Text
+		}
+	}
+
+	assert.Equal(t, "same start\n"+oldText.String()+"\nsame end\n", gotOld, "Large texts should fall back to a line diff")
+	assert.Equal(t, "same start\n"+newText.String()+"\nsame end\n", gotNew)
+	assert.Equal(t, DiffOp{Kind: DiffEqual, Text: "same start\n"}, ops[0])
+}
+
+func TestExpandTemplate(t *testing.T) {
+	t.Parallel()
+
+	content, row, col := ExpandTemplate("# Daily\n\n- Mood: {{cursor}}\n- {{selection}}", "done")
+	assert.Equal(t, "# Daily\n\n- Mood: \n- done", content)
+	assert.Equal(t, 2, row)
+	assert.Equal(t, 8, col)
+
+	content, row, col = ExpandTemplate("no marker", "")
+	assert.Equal(t, "no marker", content)
+	assert.Equal(t, 0, row)
+	assert.Equal(t, 0, col)
+}
+
+func TestStore_LoadNotes_SkipsTemplates(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	err := store.Create("note", "content")
+	assert.NoError(t, err)
+
+	templatePath := store.getTemplatePath("daily")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(templatePath), 0755))
+	assert.NoError(t, os.WriteFile(templatePath, []byte("# {{cursor}}\n"), 0644))
+
+	notes, err := store.LoadNotes()
+	assert.NoError(t, err)
+	assert.Len(t, notes, 1)
+
+	template, err := store.GetTemplate("daily")
+	assert.NoError(t, err)
+	assert.Equal(t, "# {{cursor}}", template)
+}
+
+func TestStore_ResolveName(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("project", "---\naliases: [old-project, Proj]\n---\n# Project"))
+	assert.NoError(t, store.Create("other", "---\naliases:\n  - shared\n  - project\n---\ncontent"))
+	assert.NoError(t, store.Create("third", "---\naliases: [shared]\n---\ncontent"))
+
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	name, ok := store.ResolveName("old-project")
+	assert.True(t, ok)
+	assert.Equal(t, "project", name)
+
+	name, ok = store.ResolveName("proj")
+	assert.True(t, ok, "Aliases should resolve case-insensitively")
+	assert.Equal(t, "project", name)
+
+	name, ok = store.ResolveName("project")
+	assert.True(t, ok)
+	assert.Equal(t, "project", name, "Note names take precedence over aliases")
+
+	_, ok = store.ResolveName("shared")
+	assert.False(t, ok, "Aliases declared by more than one note should be rejected")
+
+	store.SetCurrentNoteName("old-project")
+	current, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "project", current.Name)
+}
+
+func TestStore_ResolveName_RenameAndDelete(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("a", "---\naliases: [al]\n---\ncontent"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	_, err = store.RenameNote("a", "b")
+	assert.NoError(t, err)
+
+	name, ok := store.ResolveName("al")
+	assert.True(t, ok)
+	assert.Equal(t, "b", name, "Aliases should follow the renamed note")
+
+	store.SetCurrentNoteName("al")
+	current, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "b", current.Name)
+
+	assert.NoError(t, store.Delete("b"))
+
+	_, ok = store.ResolveName("al")
+	assert.False(t, ok, "Aliases of deleted notes should stop resolving")
+}
+
+func TestStore_LoadNotes_SameModTime(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	modTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, name := range []string{"charlie", "alpha", "delta", "bravo"} {
+		err := store.saveNote(name, Note{Name: name, Content: name})
+		assert.NoError(t, err)
+		assert.NoError(t, os.Chtimes(store.GetNotePath(name), modTime, modTime))
+	}
+
+	for range 3 {
+		notes, err := store.LoadNotes()
+		assert.NoError(t, err)
+
+		names := make([]string, len(notes))
+		for i, n := range notes {
+			names[i] = n.Name
+		}
+
+		assert.Equal(t, []string{"alpha", "bravo", "charlie", "delta"}, names)
+	}
+}
+
+func TestStore_Random(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	_, ok := store.Random()
+	assert.False(t, ok, "Should not pick a note from an empty store")
+
+	assert.NoError(t, store.Create("only-note", "content"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	randomNote, ok := store.Random()
+	assert.True(t, ok)
+	assert.Equal(t, "only-note", randomNote.Name)
+}
+
+func TestStore_Import_CollisionPolicies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy          CollisionPolicy
+		expectedAction  ImportAction
+		expectedName    string
+		expectedContent string
+	}{
+		{CollisionDedupe, ImportRenamed, "shared-1", "imported"},
+		{CollisionSkip, ImportSkipped, "shared", "existing"},
+		{CollisionOverwrite, ImportOverwritten, "shared", "imported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			store := setupTestStore(t)
+
+			assert.NoError(t, store.Create("shared", "existing"))
+			_, err := store.LoadNotes()
+			assert.NoError(t, err)
+
+			source := filepath.Join(t.TempDir(), "shared.md")
+			assert.NoError(t, os.WriteFile(source, []byte("imported"), 0644))
+
+			result := store.Import(source, tt.policy)
+			assert.NoError(t, result.Err)
+			assert.Equal(t, tt.expectedAction, result.Action)
+			assert.Equal(t, tt.expectedName, result.Name)
+
+			data, err := os.ReadFile(store.GetNotePath(tt.expectedName))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedContent, string(data))
+		})
+	}
+}
+
+func TestParseCollisionPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := ParseCollisionPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, CollisionDedupe, policy)
+
+	policy, err = ParseCollisionPolicy("Overwrite")
+	assert.NoError(t, err)
+	assert.Equal(t, CollisionOverwrite, policy)
+
+	_, err = ParseCollisionPolicy("replace")
+	assert.Error(t, err)
+}
+
+func TestStore_LoadNotes_Tags(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("tagged", "---\ntags: [Work, ideas]\n---\nsome words here"))
+	assert.NoError(t, store.Create("plain", "no frontmatter"))
+
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	tagged := store.notesDictionary["tagged"]
+	assert.Equal(t, []string{"Work", "ideas"}, tagged.Tags)
+	assert.True(t, tagged.HasTag("work"))
+	assert.False(t, tagged.HasTag("home"))
+
+	assert.Empty(t, store.notesDictionary["plain"].Tags)
+}
+
+func TestReadingTime(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, ReadingTime(0))
+	assert.Equal(t, 1, ReadingTime(1))
+	assert.Equal(t, 1, ReadingTime(200))
+	assert.Equal(t, 2, ReadingTime(201))
+}
+
+func TestStore_Append(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	err := store.Create("todos", "# Todos")
+	assert.NoError(t, err)
+
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+
+	before, _ := store.GetCurrentNote()
+
+	err = store.Append("- [ ] ")
+	assert.NoError(t, err)
+
+	note, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "# Todos\n- [ ] ", note.Content)
+	assert.False(t, note.UpdatedAt.Before(before.UpdatedAt))
+
+	data, err := os.ReadFile(store.GetNotePath("todos"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# Todos\n- [ ] ", string(data))
+}
+
+func TestStore_Append_NoCurrentNote(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	err := store.Append("- [ ] ")
+	assert.Error(t, err)
+}
+
+func TestSortNotes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	notes := []Note{
+		{Name: "beta", CreatedAt: now.Add(-time.Hour), UpdatedAt: now},
+		{Name: "Alpha", CreatedAt: now, UpdatedAt: now.Add(-2 * time.Hour)},
+		{Name: "gamma", CreatedAt: now.Add(-2 * time.Hour), UpdatedAt: now.Add(-time.Hour)},
+	}
+
+	names := func(notes []Note) []string {
+		result := make([]string, len(notes))
+		for i, n := range notes {
+			result[i] = n.Name
+		}
+		return result
+	}
+
+	SortNotes(notes, SortByName)
+	assert.Equal(t, []string{"Alpha", "beta", "gamma"}, names(notes))
+
+	SortNotes(notes, SortByCreated)
+	assert.Equal(t, []string{"Alpha", "beta", "gamma"}, names(notes))
+
+	SortNotes(notes, SortByModified)
+	assert.Equal(t, []string{"beta", "gamma", "Alpha"}, names(notes))
+
+	SortNotes(notes, SortByModifiedAsc)
+	assert.Equal(t, []string{"Alpha", "gamma", "beta"}, names(notes))
+
+	SortNotes(notes, SortByNameDesc)
+	assert.Equal(t, []string{"gamma", "beta", "Alpha"}, names(notes))
+}
+
+func TestSortOrder_Next(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, SortByModifiedAsc, SortByModified.Next())
+	assert.Equal(t, SortByModified, SortByCreated.Next())
+	assert.Equal(t, SortByModified, SortOrder("size").Next())
+}
+
+func TestParseSortOrder(t *testing.T) {
+	t.Parallel()
+
+	order, err := ParseSortOrder("")
+	assert.NoError(t, err)
+	assert.Equal(t, SortByModified, order)
+
+	order, err = ParseSortOrder(" Name ")
+	assert.NoError(t, err)
+	assert.Equal(t, SortByName, order)
+
+	_, err = ParseSortOrder("size")
+	assert.Error(t, err)
+}
+
+func TestStore_Names(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("beta", "b"))
+	assert.NoError(t, store.Create("alpha", "a"))
+	assert.NoError(t, os.MkdirAll(filepath.Join(store.storage, templatesDir), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(store.storage, templatesDir, "daily.md"), []byte("t"), 0644))
+
+	names, err := store.Names()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "beta"}, names)
+}
+
+func TestStore_CreateFromTemplate_KeepsExistingNote(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	templatePath := store.getTemplatePath("daily")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(templatePath), 0755))
+	assert.NoError(t, os.WriteFile(templatePath, []byte("# Daily\n\n- {{cursor}}"), 0644))
+
+	created, err := store.CreateFromTemplate("2025-01-02", "daily")
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	data, err := os.ReadFile(store.GetNotePath("2025-01-02"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# Daily\n\n- ", string(data))
+
+	edited := "# Daily\n\n- wrote the report"
+	assert.NoError(t, os.WriteFile(store.GetNotePath("2025-01-02"), []byte(edited), 0644))
+
+	created, err = store.CreateFromTemplate("2025-01-02", "daily")
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	data, err = os.ReadFile(store.GetNotePath("2025-01-02"))
+	assert.NoError(t, err)
+	assert.Equal(t, edited, string(data), "Existing note should not be reseeded from the template")
+
+	notes, err := store.LoadNotes()
+	assert.NoError(t, err)
+	assert.Len(t, notes, 1, "Second call should not create a duplicate note")
+}
+
+func TestStore_ListTemplates(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	names, err := store.ListTemplates()
+	assert.NoError(t, err)
+	assert.Empty(t, names, "A missing templates directory should list no templates")
+
+	dir := filepath.Join(store.storage, templatesDir)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0755))
+
+	for _, file := range []string{"weekly.md", "daily.md", "notes.txt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte("# Template"), 0644))
+	}
+
+	names, err = store.ListTemplates()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"daily", "weekly"}, names)
+}
+
+func TestStore_SaveAsTemplate_And_CreateNoteFromTemplate(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("meeting", "# Meeting\n\n{{cursor}}"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	name, err := store.SaveAsTemplate("meeting")
+	assert.NoError(t, err)
+	assert.Equal(t, "meeting", name)
+
+	name, err = store.SaveAsTemplate("meeting")
+	assert.NoError(t, err)
+	assert.Equal(t, "meeting-1", name, "Taken template names should get a suffix")
+
+	template, err := store.GetTemplate("meeting")
+	assert.NoError(t, err)
+	assert.Equal(t, "# Meeting\n\n{{cursor}}", template)
+
+	name, err = store.CreateNoteFromTemplate("meeting")
+	assert.NoError(t, err)
+	assert.Equal(t, "meeting-1", name, "Taken note names should get a suffix")
+
+	data, err := os.ReadFile(store.GetNotePath(name))
+	assert.NoError(t, err)
+	assert.Equal(t, "# Meeting", string(data))
+
+	_, err = store.CreateNoteFromTemplate("missing")
+	assert.Error(t, err)
+
+	assert.NoError(t, store.Create("work/todo", "- [ ] {{cursor}}"))
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+
+	name, err = store.SaveAsTemplate("work/todo")
+	assert.NoError(t, err)
+	assert.Equal(t, "todo", name, "Nested notes should be saved under their base name")
+
+	templates, err := store.ListTemplates()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"meeting", "meeting-1", "todo"}, templates)
+}
+
+func TestStore_Frontmatter_PreservedOnSave(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("tagged", "---\ntags: [work]\n---\n\n# Title"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	tagged, _ := store.GetCurrentNote()
+	assert.Equal(t, "# Title", tagged.Body(), "Frontmatter should not be part of the body")
+
+	err = store.UpdateCurrentNoteContent("---\ntags: [work, home]\n---\n\n# Title\nmore")
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(store.GetNotePath("tagged"))
+	assert.NoError(t, err)
+	assert.Equal(t, "---\ntags: [work, home]\n---\n\n# Title\nmore", string(data))
+
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+
+	tagged = store.notesDictionary["tagged"]
+	assert.Equal(t, []string{"work", "home"}, tagged.Tags)
+	assert.Equal(t, "# Title\nmore", tagged.Body())
+
+	plain := Note{Content: "# No frontmatter\n---\nnot metadata"}
+	assert.Equal(t, plain.Content, plain.Body())
+	assert.Equal(t, 1, plain.BodyLine())
+	assert.Equal(t, 5, tagged.BodyLine(), "The body should start after the frontmatter and the blank line")
+}
+
+func TestStore_Frontmatter_Title(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("meeting-2024-03", "---\ntitle: Meeting Notes, March\n---\n\n# Agenda"))
+	assert.NoError(t, store.Create("untitled", "# Agenda"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	meeting := store.notesDictionary["meeting-2024-03"]
+	assert.Equal(t, "Meeting Notes, March", meeting.Title())
+	assert.Equal(t, "untitled", store.notesDictionary["untitled"].Title(), "Notes without a title should fall back to the name")
+
+	store.SetCurrentNoteName("meeting-2024-03")
+	assert.NoError(t, store.UpdateCurrentNoteContent("# Agenda"))
+	assert.Equal(t, "meeting-2024-03", store.notesDictionary["meeting-2024-03"].Title(), "Removing the title should fall back to the name")
+}
+
+func TestStore_Search(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("groceries", "Buy Milk and eggs"))
+	assert.NoError(t, store.Create("work", "Ship the release"))
+
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	matches := store.Search("milk")
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "groceries", matches[0].Name)
+
+	assert.Empty(t, store.Search("missing"))
+	assert.Len(t, store.Search(""), 2, "Empty query should return every note")
+}
+
+func TestSnippet(t *testing.T) {
+	t.Parallel()
+
+	content := "First line\nthe quick brown fox jumps over the lazy dog"
+
+	assert.Equal(t, "…brown FOX jumps…", Snippet(strings.Replace(content, "fox", "FOX", 1), "fox", 6))
+	assert.Equal(t, "First line the…", Snippet(content, "first", 10))
+	assert.Equal(t, "", Snippet(content, "cat", 10))
+}
+
+func TestStore_Trash(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("draft", "first draft"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Delete("draft"))
+	assert.NoFileExists(t, store.GetNotePath("draft"))
+	assert.FileExists(t, store.getTrashPath("draft"))
+
+	trash := store.ListTrash()
+	assert.Len(t, trash, 1)
+	assert.Equal(t, "draft", trash[0].Name)
+	assert.Equal(t, "first draft", trash[0].Content)
+
+	notes, err := store.LoadNotes()
+	assert.NoError(t, err)
+	assert.Empty(t, notes, "Trashed notes should not be loaded")
+
+	// a new note took the name in the meantime
+	assert.NoError(t, store.Create("draft", "second draft"))
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Restore("draft"))
+
+	restored, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "draft-1", restored.Name)
+	assert.Equal(t, "first draft", restored.Content)
+	assert.Empty(t, store.ListTrash())
+
+	assert.Error(t, store.Restore("missing"))
+
+	assert.NoError(t, store.Delete("draft"))
+	assert.NoError(t, store.EmptyTrash())
+	assert.Empty(t, store.ListTrash())
+	assert.NoDirExists(t, filepath.Join(store.storage, trashDir))
+}
+
+func TestStore_Undelete(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("draft", "first draft"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+	assert.NoError(t, store.Delete("draft"))
+
+	assert.NoError(t, store.Create("draft", "second draft"))
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+	assert.NoError(t, store.TogglePin("draft"))
+	assert.NoError(t, store.Delete("draft"))
+
+	assert.NoError(t, store.Undelete("draft"))
+
+	restored, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "draft", restored.Name)
+	assert.Equal(t, "second draft", restored.Content, "The note deleted last should be restored")
+	assert.True(t, restored.Pinned, "The note should be pinned again")
+
+	trash := store.ListTrash()
+	assert.Len(t, trash, 1)
+	assert.Equal(t, "first draft", trash[0].Content)
+
+	assert.Error(t, store.Undelete("draft"), "A note can only be undeleted once")
+}
+
+func TestStore_Trash_Folders(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("work/todo", "work todo"))
+	assert.NoError(t, store.Create("home/todo", "home todo"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Delete("work/todo"))
+	assert.NoError(t, store.Delete("home/todo"))
+	assert.FileExists(t, store.getTrashPath("work/todo"))
+	assert.FileExists(t, store.getTrashPath("home/todo"), "Notes of different folders should not collide in the trash")
+
+	trash := store.ListTrash()
+	assert.Len(t, trash, 2)
+
+	names := []string{trash[0].Name, trash[1].Name}
+	assert.ElementsMatch(t, []string{"work/todo", "home/todo"}, names)
+
+	for _, n := range trash {
+		assert.Equal(t, strings.Split(n.Name, "/")[0], n.Folder)
+	}
+
+	assert.NoError(t, store.Restore("work/todo"))
+
+	restored, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "work/todo", restored.Name)
+	assert.Equal(t, "work", restored.Folder)
+	assert.Equal(t, "work todo", restored.Content)
+	assert.FileExists(t, store.GetNotePath("work/todo"))
+	assert.NoDirExists(t, filepath.Join(store.storage, trashDir, "work"), "Emptied folders should be removed from the trash")
+	assert.Len(t, store.ListTrash(), 1)
+}
+
+func TestStore_Archive(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("old", "old ideas"))
+	assert.NoError(t, store.Create("new", "new ideas"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Archive("old"))
+	assert.NoFileExists(t, store.GetNotePath("old"))
+	assert.FileExists(t, store.getArchivePath("old"))
+
+	_, ok := store.ResolveName("old")
+	assert.False(t, ok)
+	assert.Len(t, store.GetNotes(), 1)
+
+	archived := store.ListArchived()
+	assert.Len(t, archived, 1)
+	assert.Equal(t, "old ideas", archived[0].Content)
+
+	notes, err := store.LoadNotes()
+	assert.NoError(t, err)
+	assert.Len(t, notes, 1, "Archived notes should not be loaded")
+
+	assert.NoError(t, store.Unarchive("old"))
+
+	current, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "old", current.Name)
+	assert.Empty(t, store.ListArchived())
+
+	assert.Error(t, store.Archive("missing"))
+	assert.Error(t, store.Unarchive("missing"))
+}
+
+func TestStore_Archive_Folders(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("work/todo", "work todo"))
+	assert.NoError(t, store.Create("todo", "todo"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Archive("work/todo"))
+	assert.NoError(t, store.Archive("todo"))
+	assert.FileExists(t, store.getArchivePath("work/todo"))
+	assert.FileExists(t, store.getArchivePath("todo"))
+
+	archived := store.ListArchived()
+	assert.Len(t, archived, 2)
+	assert.ElementsMatch(t, []string{"work/todo", "todo"}, []string{archived[0].Name, archived[1].Name})
+
+	assert.NoError(t, store.Unarchive("work/todo"))
+
+	current, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "work/todo", current.Name, "Unarchived notes should go back into their folder")
+	assert.Equal(t, "work", current.Folder)
+	assert.Equal(t, "work todo", current.Content)
+
+	notes, err := store.LoadNotes()
+	assert.NoError(t, err)
+	assert.Len(t, notes, 1)
+	assert.Equal(t, "work/todo", notes[0].Name)
+}
+
+func TestStore_TogglePin(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("reference", "ref"))
+	assert.NoError(t, store.Create("other", "other"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.TogglePin("reference"))
+	assert.True(t, store.notesDictionary["reference"].Pinned)
+
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+	assert.True(t, store.notesDictionary["reference"].Pinned, "Pins should persist across loads")
+	assert.False(t, store.notesDictionary["other"].Pinned)
+
+	_, err = store.RenameNote("reference", "manual")
+	assert.NoError(t, err)
+	assert.True(t, store.loadPinned()["manual"], "Renamed notes should stay pinned")
+
+	assert.NoError(t, store.TogglePin("manual"))
+	assert.False(t, store.notesDictionary["manual"].Pinned)
+	assert.Empty(t, store.loadPinned())
+
+	assert.NoError(t, store.TogglePin("MANUAL"))
+	assert.True(t, store.notesDictionary["manual"].Pinned, "Pins should ignore the case of the name")
+
+	for _, n := range store.GetNotes() {
+		assert.Equal(t, n.Name == "manual", n.Pinned)
+	}
+
+	assert.NoError(t, store.Delete("manual"))
+	assert.Empty(t, store.loadPinned(), "Deleted notes should be unpinned")
+
+	assert.NoError(t, store.Create("manual", "new"))
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+	assert.False(t, store.notesDictionary["manual"].Pinned, "A note taking the name of a deleted one should not be pinned")
+
+	assert.Error(t, store.TogglePin("missing"))
+}
+
+func TestStore_Duplicate(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("my-note", "original"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	duplicate, err := store.Duplicate("my-note")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-note-copy", duplicate.Name)
+
+	data, err := os.ReadFile(store.GetNotePath("my-note-copy"))
+	assert.NoError(t, err)
+	assert.Equal(t, "original", string(data))
+
+	current, _ := store.GetCurrentNote()
+	assert.Equal(t, "my-note-copy", current.Name)
+
+	second, err := store.Duplicate("my-note")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-note-copy-1", second.Name)
+
+	_, err = store.Duplicate("missing")
+	assert.Error(t, err)
+}
+
+func TestStore_Create_CaseInsensitiveCollision(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("Note", "upper"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Create("note", "lower"))
+
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+	assert.Len(t, store.GetNotes(), 2)
+
+	current, _ := store.GetCurrentNote()
+	assert.Equal(t, "note-1", current.Name)
+
+	name, ok := store.ResolveName("NOTE")
+	assert.True(t, ok)
+	assert.Equal(t, "Note", name)
+
+	renamed, err := store.RenameNote("Note", "NOTE")
+	assert.NoError(t, err)
+	assert.Equal(t, "NOTE", renamed.Name)
+
+	renamed, err = store.RenameNote("NOTE", "Note-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Note-1-1", renamed.Name)
+}
+
+func TestNameFromHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		content  string
+		expected string
+		ok       bool
+	}{
+		{"# My Note\nbody", "my-note", true},
+		{"## Release -- notes #2", "release-notes-2", true},
+		{"#", "", false},
+		{"no header\n# later", "", false},
+	}
+
+	for _, tt := range tests {
+		name, ok := NameFromHeader(tt.content)
+		assert.Equal(t, tt.expected, name, tt.content)
+		assert.Equal(t, tt.ok, ok, tt.content)
+	}
+}
+
+func TestExpandNameTemplate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.March, 5, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		template string
+		title    string
+		expected string
+	}{
+		{"2006-01-02-{title}", "groceries", "2026-03-05-groceries"},
+		{"2006-01-02-{title}", "", "2026-03-05"},
+		{"{title}_2006", "", "2026"},
+		{"journal/2006/01-02", "ignored", "journal/2026/03-05"},
+		{"{title}", "", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ExpandNameTemplate(tt.template, tt.title, now), tt.template)
+	}
+}
+
+func TestStore_Drafts(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("my-note", "saved"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	_, ok := store.GetDraft("my-note")
+	assert.False(t, ok)
+
+	assert.NoError(t, store.SaveDraft("my-note", "unsaved"))
+
+	// the draft must be newer than the note
+	past := time.Now().Add(-time.Minute)
+	assert.NoError(t, os.Chtimes(store.GetNotePath("my-note"), past, past))
+
+	draft, ok := store.GetDraft("my-note")
+	assert.True(t, ok)
+	assert.Equal(t, "unsaved", draft)
+
+	store.SetCurrentNoteName("my-note")
+	assert.NoError(t, store.UpdateCurrentNoteContent("unsaved"))
+
+	_, ok = store.GetDraft("my-note")
+	assert.False(t, ok)
+
+	assert.NoError(t, store.DeleteDraft("my-note"))
+}
+
+func TestStore_Drafts_RenameAndDelete(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("my-note", "saved"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.SaveDraft("my-note", "unsaved"))
+
+	past := time.Now().Add(-time.Minute)
+	assert.NoError(t, os.Chtimes(store.GetNotePath("my-note"), past, past))
+
+	_, err = store.RenameNote("my-note", "renamed")
+	assert.NoError(t, err)
+
+	assert.NoFileExists(t, store.getDraftPath("my-note"))
+
+	draft, ok := store.GetDraft("renamed")
+	assert.True(t, ok, "The draft should follow the renamed note")
+	assert.Equal(t, "unsaved", draft)
+
+	assert.NoError(t, store.Delete("renamed"))
+	assert.NoFileExists(t, store.getDraftPath("renamed"), "The draft should be deleted with the note")
+
+	assert.NoError(t, store.Create("renamed", "new"))
+	_, ok = store.GetDraft("renamed")
+	assert.False(t, ok)
+}
+
+func TestStore_AddTag_RemoveTag(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("plain", "Body"))
+	assert.NoError(t, store.Create("tagged", "---\naliases: [t]\ntags: [work]\n---\n\nBody"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.AddTag("plain", "idea"))
+	assert.NoError(t, store.AddTag("tagged", "idea"))
+	assert.NoError(t, store.AddTag("tagged", "WORK"))
+
+	data, err := os.ReadFile(store.GetNotePath("plain"))
+	assert.NoError(t, err)
+	assert.Equal(t, "---\ntags:\n  - idea\n---\n\nBody", string(data))
+
+	data, err = os.ReadFile(store.GetNotePath("tagged"))
+	assert.NoError(t, err)
+	assert.Equal(t, "---\naliases: [t]\ntags: [work, idea]\n---\n\nBody", string(data))
+
+	assert.NoError(t, store.RemoveTag("plain", "IDEA"))
+	assert.NoError(t, store.RemoveTag("tagged", "work"))
+
+	data, err = os.ReadFile(store.GetNotePath("plain"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Body", string(data))
+
+	notes, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	for _, n := range notes {
+		if n.Name == "tagged" {
+			assert.Equal(t, []string{"idea"}, n.Tags)
+			assert.Equal(t, []string{"t"}, n.Aliases)
+		}
+	}
+
+	assert.Error(t, store.AddTag("missing", "idea"))
+}
+
+func TestStore_Merge(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("target", "first"))
+	assert.NoError(t, store.Create("source", "second"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.Error(t, store.Merge("target", "target"))
+	assert.Error(t, store.Merge("target", "missing"))
+
+	assert.NoError(t, store.Merge("target", "source"))
+
+	data, err := os.ReadFile(store.GetNotePath("target"))
+	assert.NoError(t, err)
+	assert.Equal(t, "first\n\n---\n\n# source\n\nsecond", string(data))
+
+	_, err = os.Stat(store.GetNotePath("source"))
+	assert.True(t, os.IsNotExist(err))
+	assert.Len(t, store.GetNotes(), 1)
+
+	current, _ := store.GetCurrentNote()
+	assert.Equal(t, "target", current.Name)
+}
+
+func TestStore_CopyNotes(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("first", "one"))
+	assert.NoError(t, store.Create("second", "two"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.CopyNotes([]string{"first", "second"}))
+
+	clipboard := store.clipboardService.(*mockClipboardService)
+	assert.Equal(t, "# first\n\none\n\n# second\n\ntwo", clipboard.CopiedText)
+
+	assert.Error(t, store.CopyNotes([]string{"first", "missing"}))
+	assert.Equal(t, "# first\n\none\n\n# second\n\ntwo", clipboard.CopiedText, "Nothing should be copied when a note is missing")
+}
+
+func TestStore_CopyPlainText(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	content := "---\ntags: [work]\n---\n# Title\n\nSome **bold** and _italic_ with `a*b*` in [docs](https://x.io)."
+
+	assert.NoError(t, store.CopyPlainText(content))
+
+	clipboard := store.clipboardService.(*mockClipboardService)
+	assert.Equal(t, "Title\n\nSome bold and italic with a*b* in docs.", clipboard.CopiedText)
+}
+
+func TestStore_CopyCodeBlock(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	content := "# Snippets\n\n```go\nfmt.Println(1)\n```\n\ntext\n\n```\nls -la\ncd ..\n```"
+	assert.NoError(t, store.Create("snippets", content))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+	store.SetCurrentNoteName("snippets")
+
+	block, err := store.CopyCodeBlock(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "", block.Language)
+
+	clipboard := store.clipboardService.(*mockClipboardService)
+	assert.Equal(t, "ls -la\ncd ..", clipboard.CopiedText)
+
+	block, err = store.CopyCodeBlock(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "go", block.Language)
+	assert.Equal(t, "fmt.Println(1)", clipboard.CopiedText)
+
+	_, err = store.CopyCodeBlock(3)
+	assert.Error(t, err)
+	_, err = store.CopyCodeBlock(0)
+	assert.Error(t, err)
+}
+
+func TestStore_Backlinks(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("project", "---\naliases: [proj]\n---\n# Project"))
+	assert.NoError(t, store.Create("by name", "see [[Project]]"))
+	assert.NoError(t, store.Create("by alias", "see [[proj|the project]] and [[project]]"))
+	assert.NoError(t, store.Create("in code", "`[[project]]`\n```\n[[project]]\n```"))
+	assert.NoError(t, store.Create("unrelated", "see [[other]]"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"by alias", "by name"}, store.Backlinks("proj"))
+
+	assert.Empty(t, store.Backlinks("unrelated"))
+	assert.Nil(t, store.Backlinks("missing"))
+}
+
+func TestStore_ExtractLines(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("source", "# Source\n\none\ntwo\nthree"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+	store.SetCurrentNoteName("source")
+
+	name, err := store.ExtractLines(3, 4, "extracted")
+	assert.NoError(t, err)
+	assert.Equal(t, "extracted", name)
+
+	content, err := os.ReadFile(filepath.Join(store.storage, "extracted.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, "one\ntwo", string(content))
+
+	store.SetCurrentNoteName("source")
+
+	for _, r := range [][2]int{{0, 1}, {3, 2}, {4, 6}} {
+		_, err := store.ExtractLines(r[0], r[1], "invalid")
+		assert.Error(t, err, "range %d-%d should be out of bounds", r[0], r[1])
+	}
+}
+
+func TestStore_Encryption(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("plain", "# Plain"))
+	assert.False(t, store.HasPassphrase())
+
+	assert.NoError(t, store.Unlock("secret"))
+	assert.True(t, store.HasPassphrase())
+	assert.True(t, store.IsEncrypted())
+
+	assert.NoError(t, store.Create("secret", "# Secret\n\nhidden text"))
+
+	data, err := os.ReadFile(store.GetNotePath("secret"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), encryptedHeader))
+	assert.NotContains(t, string(data), "hidden text")
+
+	notes, err := store.LoadNotes()
+	assert.NoError(t, err)
+	assert.Len(t, notes, 2, "plaintext and encrypted notes should load side by side")
+
+	secret, ok := store.notesDictionary["secret"]
+	assert.True(t, ok)
+	assert.Equal(t, "# Secret\n\nhidden text", secret.Content)
+
+	locked := setupTestStore(t)
+	locked.storage = store.storage
+
+	assert.ErrorIs(t, locked.Unlock("wrong"), ErrWrongPassphrase)
+	assert.False(t, locked.IsEncrypted())
+
+	_, err = locked.LoadNotes()
+	assert.ErrorContains(t, err, "secret.md is encrypted")
+
+	assert.NoError(t, locked.Unlock("secret"))
+	_, err = locked.LoadNotes()
+	assert.NoError(t, err)
+}
+
+func TestDecrypt_WrongKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := deriveKey("one", []byte("salt"))
+	assert.NoError(t, err)
+	other, err := deriveKey("two", []byte("salt"))
+	assert.NoError(t, err)
+
+	sealed, err := encrypt(key, []byte("content"))
+	assert.NoError(t, err)
+
+	plaintext, err := decrypt(key, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "content", string(plaintext))
+
+	_, err = decrypt(other, sealed)
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestStore_RenameNote_Scratch(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create(ScratchNote, "quick thought"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	_, err = store.RenameNote(ScratchNote, "ideas")
+	assert.ErrorContains(t, err, "can't be renamed")
+	assert.FileExists(t, store.GetNotePath(ScratchNote))
+
+	store.SetCurrentNoteName(ScratchNote)
+	_, err = store.RenameCurrentNote("ideas")
+	assert.Error(t, err)
+	assert.NoFileExists(t, store.GetNotePath("ideas"))
+}
+
+func TestStore_RenameAll(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	for _, name := range []string{"apollo-plan", "apollo-notes", "gemini-notes", "other"} {
+		assert.NoError(t, store.Create(name, name))
+	}
+
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	store.SetCurrentNoteName("apollo-plan")
+
+	expected := []Rename{
+		{From: "apollo-notes", To: "gemini-notes-1"},
+		{From: "apollo-plan", To: "gemini-plan"},
+	}
+
+	renames, err := store.RenameAll("apollo", "gemini", true)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, renames)
+	assert.FileExists(t, store.GetNotePath("apollo-plan"))
+
+	renames, err = store.RenameAll("apollo", "gemini", false)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, renames)
+	assert.NoFileExists(t, store.GetNotePath("apollo-plan"))
+	assert.FileExists(t, store.GetNotePath("gemini-notes-1"))
+	assert.FileExists(t, store.GetNotePath("gemini-plan"))
+
+	current, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "gemini-plan", current.Name)
+
+	_, err = store.RenameAll("", "x", false)
+	assert.Error(t, err)
+	_, err = store.RenameAll("other", "a/b", false)
+	assert.Error(t, err)
+}
+
+func TestStore_Extension(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+	store.extension = ".txt"
+
+	assert.NoError(t, os.WriteFile(filepath.Join(store.storage, "ignored.md"), []byte("other"), 0644))
+	assert.NoError(t, store.Create("plain", "text"))
+	assert.FileExists(t, filepath.Join(store.storage, "plain.txt"))
+
+	notes, err := store.LoadNotes()
+	assert.NoError(t, err)
+	assert.Len(t, notes, 1)
+	assert.Equal(t, "plain", notes[0].Name)
+
+	_, err = store.RenameNote("plain", "renamed")
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(store.storage, "renamed.txt"))
+
+	assert.NoError(t, store.Delete("renamed"))
+	trashed := store.ListTrash()
+	assert.Len(t, trashed, 1)
+	assert.Equal(t, "renamed", trashed[0].Name)
+}
+
+func TestStore_SwitchStorage(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("personal", "home"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	work := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(work, "plan.md"), []byte("# Plan"), 0644))
+
+	notes, err := store.SwitchStorage(work)
+	assert.NoError(t, err)
+	assert.Len(t, notes, 1)
+	assert.Equal(t, "plan", notes[0].Name)
+
+	_, ok := store.notesDictionary["personal"]
+	assert.False(t, ok, "Notes of the previous storage should be dropped")
+
+	current, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "plan", current.Name)
+	assert.Equal(t, filepath.Join(work, "plan.md"), store.GetNotePath("plan"))
+
+	// a note that can't be read fails the load
+	broken := t.TempDir()
+	assert.NoError(t, os.Symlink(filepath.Join(broken, "missing"), filepath.Join(broken, "broken.md")))
+
+	_, err = store.SwitchStorage(broken)
+	assert.Error(t, err)
+
+	current, ok = store.GetCurrentNote()
+	assert.True(t, ok, "The store should stay on its storage when the notes fail to load")
+	assert.Equal(t, "plan", current.Name)
+	assert.Equal(t, filepath.Join(work, "plan.md"), store.GetNotePath("plan"))
+}
+
+func TestStore_TabsToSpaces(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("tabs", "a\tb\n\tc"))
+
+	data, err := os.ReadFile(store.GetNotePath("tabs"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a\tb\n\tc", string(data), "Tabs should be kept unless tabs_to_spaces is set")
+
+	store.tabsToSpaces = true
+	store.tabWidth = 4
+
+	_, err = store.LoadNotes()
+	assert.NoError(t, err)
+	store.SetCurrentNoteName("tabs")
+	assert.NoError(t, store.UpdateCurrentNoteContent("ab\tc\n\td"))
+
+	data, err = os.ReadFile(store.GetNotePath("tabs"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ab  c\n    d", string(data))
+
+	assert.NoError(t, store.Create("spaces", "\t\tx"))
+
+	data, err = os.ReadFile(store.GetNotePath("spaces"))
+	assert.NoError(t, err)
+	assert.Equal(t, "        x", string(data))
+
+	source := filepath.Join(t.TempDir(), "imported.md")
+	assert.NoError(t, os.WriteFile(source, []byte("\tx"), 0644))
+	assert.NoError(t, store.Import(source, CollisionDedupe).Err)
+
+	data, err = os.ReadFile(store.GetNotePath("imported"))
+	assert.NoError(t, err)
+	assert.Equal(t, "    x", string(data))
+}
+
+func TestStore_NestedNotes(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	assert.NoError(t, store.Create("work/todo", "ship it"))
+	assert.NoError(t, store.Create("home/todo", "water plants"))
+	assert.NoError(t, store.Create("todo", "top level"))
+	assert.FileExists(t, filepath.Join(store.storage, "work", "todo.md"))
+
+	notes, err := store.LoadNotes()
+	assert.NoError(t, err)
+	assert.Len(t, notes, 3)
+
+	names, err := store.Names()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"home/todo", "todo", "work/todo"}, names)
+
+	store.SetCurrentNoteName("work/todo")
+	current, ok := store.GetCurrentNote()
+	assert.True(t, ok)
+	assert.Equal(t, "ship it", current.Content)
+	assert.Equal(t, "work", current.Folder)
+
+	assert.NoError(t, store.UpdateCurrentNoteContent("shipped"))
+	data, err := os.ReadFile(store.GetNotePath("work/todo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "shipped", string(data))
+
+	renamed, err := store.RenameNote("work/todo", "archive/2024/todo")
+	assert.NoError(t, err)
+	assert.Equal(t, "archive/2024/todo", renamed.Name)
+	assert.FileExists(t, filepath.Join(store.storage, "archive", "2024", "todo.md"))
+
+	for _, name := range []string{"../escape", "work//todo", ".hidden/todo", "a/./b"} {
+		assert.Error(t, store.Create(name, ""), name)
+	}
+
+	_, err = store.RenameNote("todo", "../todo")
+	assert.Error(t, err)
+}
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	words := tokenize("The Kubernetes cluster, and the kubernetes-operator: v2 42 it's go_lang https://example.com")
+	assert.Equal(t, []string{"kubernetes", "cluster", "kubernetes-operator", "go_lang", "example"}, words)
+}
+
+func TestStore_SuggestTags(t *testing.T) {
+	t.Parallel()
+	store := setupTestStore(t)
+
+	content := "---\ntags: [deploy]\n---\n" +
+		"# Deploy the cluster\n\n" +
+		"The cluster runs on kubernetes. Deploy the kubernetes cluster weekly.\n" +
+		"```\ncluster cluster cluster terraform terraform terraform terraform\n```\n" +
+		"Terraform provisions it."
+
+	assert.NoError(t, store.Create("infra", content))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"cluster", "kubernetes"}, store.SuggestTags("infra", 2))
+	assert.Equal(t, []string{"cluster", "kubernetes", "provisions", "runs", "terraform", "weekly"}, store.SuggestTags("infra", 10))
+	assert.Empty(t, store.SuggestTags("missing", 3))
+}
+
+func TestComputeStats(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	notes := []Note{
+		{Name: "a", Content: "one two three", Tags: []string{"Work"}, UpdatedAt: now, Byte: []byte("one two three")},
+		{Name: "b", Content: "four", Tags: []string{"work", "home"}, UpdatedAt: now.Add(-time.Hour), Byte: []byte("four")},
+		{Name: "c", Content: "five six", UpdatedAt: now.Add(-time.Minute), Byte: []byte("five six")},
+	}
+
+	stats := ComputeStats(notes)
+
+	assert.Equal(t, 3, stats.Notes)
+	assert.Equal(t, 6, stats.Words)
+	assert.Equal(t, int64(25), stats.Size)
+	assert.Equal(t, 2, stats.AverageWords)
+	assert.Equal(t, "b", stats.Oldest.Name)
+	assert.Equal(t, "a", stats.Newest.Name)
+	assert.Equal(t, map[string]int{"work": 2, "home": 1}, stats.Tags)
+
+	empty := ComputeStats(nil)
+	assert.Equal(t, 0, empty.AverageWords)
+	assert.Nil(t, empty.Oldest)
+}
+
+func TestStore_GitAutoCommit(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	store := setupTestStore(t)
+	store.gitAutoCommit = true
+
+	// not a repository yet, so nothing is committed
+	assert.NoError(t, store.Create("untracked", "content"))
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.name", "notes"},
+		{"config", "user.email", "notes@example.com"},
+	} {
+		_, err := store.git(args...)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, store.Create("idea", "first"))
+	_, err := store.LoadNotes()
+	assert.NoError(t, err)
+
+	store.SetCurrentNoteName("idea")
+	assert.NoError(t, store.UpdateCurrentNoteContent("second"))
+	assert.NoError(t, store.UpdateCurrentNoteContent("second"), "Saving an unchanged note should not fail")
+
+	created, err := store.CreateFromTemplate("2025-01-02", "")
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	_, err = store.Duplicate("idea")
+	assert.NoError(t, err)
+
+	source := filepath.Join(t.TempDir(), "idea.md")
+	assert.NoError(t, os.WriteFile(source, []byte("imported"), 0644))
+	assert.NoError(t, store.Import(source, CollisionOverwrite).Err)
+
+	log, err := store.git("log", "--format=%s")
+	assert.NoError(t, err)
+	assert.Equal(t, "update idea\ncreate idea-copy\ncreate 2025-01-02\nupdate idea\ncreate idea\n", log)
+
+	store.gitAutoCommit = false
+	assert.NoError(t, store.Delete("idea"))
+
+	log, err = store.git("log", "--format=%s")
+	assert.NoError(t, err)
+	assert.Equal(t, "update idea\ncreate idea-copy\ncreate 2025-01-02\nupdate idea\ncreate idea\n", log, "Nothing should be committed when disabled")
+}
+
+func TestStore_GitAutoCommit_NotesOnly(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	store := setupTestStore(t)
+	store.gitAutoCommit = true
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"config", "user.name", "notes"},
+		{"config", "user.email", "notes@example.com"},
+	} {
+		_, err := store.git(args...)
+		assert.NoError(t, err)
+	}
+
+	// the config and the state of the UI are kept in the default storage
+	for _, name := range []string{".config.toml", ".state.json"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(store.storage, name), []byte("{}"), 0644))
+	}
+
+	assert.NoError(t, store.Create("idea", "first"))
+
+	files, err := store.git("ls-files")
+	assert.NoError(t, err)
+	assert.Equal(t, "idea.md\n", files)
+
+	// a hook rejecting every commit makes the auto-commit fail
+	hook := filepath.Join(store.storage, ".git", "hooks", "pre-commit")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(hook), 0755))
+	assert.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0755))
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(store.storage, templatesDir), 0755))
+	assert.NoError(t, os.WriteFile(store.getTemplatePath("meeting"), []byte("# Meeting"), 0644))
+
+	name, err := store.CreateNoteFromTemplate("meeting")
+	assert.ErrorIs(t, err, ErrAutoCommit)
+	assert.Equal(t, "meeting", name, "The name of the created note should be returned when only the commit failed")
+	assert.FileExists(t, store.GetNotePath("meeting"))
+}
@@ -0,0 +1,17 @@
+package note
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// creationTime reads the creation time from the Windows file attributes
+func creationTime(info os.FileInfo) time.Time {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return info.ModTime()
+	}
+
+	return time.Unix(0, data.CreationTime.Nanoseconds())
+}
@@ -0,0 +1,108 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// pinnedFile lists the names of the pinned notes, one per line
+const pinnedFile = ".pinned"
+
+func (s Store) getPinnedPath() string {
+	return filepath.Join(s.storage, pinnedFile)
+}
+
+// loadPinned returns the pinned notes, by the key of their name
+func (s Store) loadPinned() map[string]bool {
+	pinned := make(map[string]bool)
+
+	data, err := os.ReadFile(s.getPinnedPath())
+	if err != nil {
+		return pinned
+	}
+
+	for name := range strings.SplitSeq(string(data), "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			pinned[noteKey(name)] = true
+		}
+	}
+
+	return pinned
+}
+
+func (s Store) savePinned(pinned map[string]bool) error {
+	names := make([]string, 0, len(pinned))
+	for name := range pinned {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	if err := writeFileAtomic(s.getPinnedPath(), []byte(strings.Join(names, "\n"))); err != nil {
+		return fmt.Errorf("failed to save pinned notes: %w", err)
+	}
+
+	return nil
+}
+
+// TogglePin pins the note to the top of the list, or unpins it
+func (s *Store) TogglePin(name string) error {
+	note, ok := s.notesDictionary[noteKey(name)]
+	if !ok {
+		return errors.New("note not found")
+	}
+
+	key := noteKey(name)
+	pinned := s.loadPinned()
+
+	if pinned[key] {
+		delete(pinned, key)
+	} else {
+		pinned[key] = true
+	}
+
+	if err := s.savePinned(pinned); err != nil {
+		return err
+	}
+
+	note.Pinned = pinned[key]
+	s.notesDictionary[key] = note
+
+	for i := range s.notes {
+		if s.notes[i].Name == note.Name {
+			s.notes[i].Pinned = note.Pinned
+		}
+	}
+
+	return nil
+}
+
+// movePin keeps a note pinned after it is renamed
+func (s Store) movePin(currentName, newName string) error {
+	pinned := s.loadPinned()
+	if !pinned[noteKey(currentName)] {
+		return nil
+	}
+
+	delete(pinned, noteKey(currentName))
+	pinned[noteKey(newName)] = true
+
+	return s.savePinned(pinned)
+}
+
+// removePin unpins a deleted note, so that a note created
+// with the same name later doesn't start out pinned
+func (s Store) removePin(name string) error {
+	pinned := s.loadPinned()
+	if !pinned[noteKey(name)] {
+		return nil
+	}
+
+	delete(pinned, noteKey(name))
+
+	return s.savePinned(pinned)
+}
@@ -0,0 +1,60 @@
+package note
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Search returns the notes whose content contains the query, ignoring case.
+// An empty query returns every note.
+func (s Store) Search(query string) []Note {
+	if query == "" {
+		return s.notes
+	}
+
+	query = strings.ToLower(query)
+
+	var matches []Note
+	for _, n := range s.notes {
+		if strings.Contains(strings.ToLower(n.Content), query) {
+			matches = append(matches, n)
+		}
+	}
+
+	return matches
+}
+
+// Snippet returns the first match of query in content, ignoring case, with up to
+// radius characters of context on each side, flattened to a single line.
+// It returns an empty string when there is no match.
+func Snippet(content, query string, radius int) string {
+	runes := []rune(content)
+	queryLength := utf8.RuneCountInString(query)
+
+	if queryLength == 0 {
+		return ""
+	}
+
+	for i := 0; i+queryLength <= len(runes); i++ {
+		if !strings.EqualFold(string(runes[i:i+queryLength]), query) {
+			continue
+		}
+
+		start := max(0, i-radius)
+		end := min(len(runes), i+queryLength+radius)
+
+		snippet := strings.Join(strings.Fields(string(runes[start:end])), " ")
+
+		if start > 0 {
+			snippet = "…" + snippet
+		}
+
+		if end < len(runes) {
+			snippet += "…"
+		}
+
+		return snippet
+	}
+
+	return ""
+}
@@ -0,0 +1,71 @@
+package note
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortOrder decides how a list of notes is ordered
+type SortOrder string
+
+const (
+	SortByModified    SortOrder = "modified"
+	SortByModifiedAsc SortOrder = "modified-asc"
+	SortByName        SortOrder = "name"
+	SortByNameDesc    SortOrder = "name-desc"
+	SortByCreated     SortOrder = "created"
+)
+
+// SortOrders lists every order in the sequence they are cycled through
+var SortOrders = []SortOrder{SortByModified, SortByModifiedAsc, SortByName, SortByNameDesc, SortByCreated}
+
+// Next returns the order following o, wrapping around after the last one
+func (o SortOrder) Next() SortOrder {
+	i := slices.Index(SortOrders, o)
+	return SortOrders[(i+1)%len(SortOrders)]
+}
+
+func ParseSortOrder(value string) (SortOrder, error) {
+	switch order := SortOrder(strings.ToLower(strings.TrimSpace(value))); order {
+	case "":
+		return SortByModified, nil
+	case SortByModified, SortByModifiedAsc, SortByName, SortByNameDesc, SortByCreated:
+		return order, nil
+	default:
+		return "", fmt.Errorf("invalid sort order %q, expected modified, modified-asc, name, name-desc or created", value)
+	}
+}
+
+// SortNotes orders notes in place. Dates sort from the most recent
+// and names alphabetically, ignoring case, unless the order is reversed.
+func SortNotes(notes []Note, order SortOrder) {
+	switch order {
+	case SortByCreated:
+		slices.SortStableFunc(notes, compareByCreatedAt)
+	case SortByName:
+		slices.SortStableFunc(notes, compareByName)
+	case SortByNameDesc:
+		slices.SortStableFunc(notes, func(i, j Note) int { return compareByName(j, i) })
+	case SortByModifiedAsc:
+		slices.SortStableFunc(notes, func(i, j Note) int { return compareByUpdatedAt(j, i) })
+	default:
+		slices.SortStableFunc(notes, compareByUpdatedAt)
+	}
+}
+
+func compareByCreatedAt(i, j Note) int {
+	if c := j.CreatedAt.Compare(i.CreatedAt); c != 0 {
+		return c
+	}
+
+	return strings.Compare(i.Name, j.Name)
+}
+
+func compareByName(i, j Note) int {
+	if c := strings.Compare(strings.ToLower(i.Name), strings.ToLower(j.Name)); c != 0 {
+		return c
+	}
+
+	return strings.Compare(i.Name, j.Name)
+}
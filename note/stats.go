@@ -0,0 +1,55 @@
+package note
+
+import (
+	"strings"
+	"time"
+)
+
+// Stats summarises a collection of notes
+type Stats struct {
+	Notes        int            `json:"notes"`
+	Words        int            `json:"words"`
+	Size         int64          `json:"size"`
+	AverageWords int            `json:"average_words"`
+	Oldest       *StatsNote     `json:"oldest,omitempty"`
+	Newest       *StatsNote     `json:"newest,omitempty"`
+	Tags         map[string]int `json:"tags"`
+}
+
+// StatsNote identifies a note in the stats without its content
+type StatsNote struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ComputeStats counts the words, bytes and tags of the notes and finds
+// the least and most recently modified ones. Tags are counted lower case.
+func ComputeStats(notes []Note) Stats {
+	stats := Stats{
+		Notes: len(notes),
+		Tags:  make(map[string]int),
+	}
+
+	for _, n := range notes {
+		stats.Words += WordCount(n.Content)
+		stats.Size += int64(len(n.Byte))
+
+		for _, tag := range n.Tags {
+			stats.Tags[strings.ToLower(tag)]++
+		}
+
+		if stats.Oldest == nil || n.UpdatedAt.Before(stats.Oldest.UpdatedAt) {
+			stats.Oldest = &StatsNote{Name: n.Name, UpdatedAt: n.UpdatedAt}
+		}
+
+		if stats.Newest == nil || n.UpdatedAt.After(stats.Newest.UpdatedAt) {
+			stats.Newest = &StatsNote{Name: n.Name, UpdatedAt: n.UpdatedAt}
+		}
+	}
+
+	if stats.Notes > 0 {
+		stats.AverageWords = stats.Words / stats.Notes
+	}
+
+	return stats
+}
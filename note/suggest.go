@@ -0,0 +1,99 @@
+package note
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ionut-t/notes/markdown"
+)
+
+// minTagLength is the length of the shortest word suggested as a tag
+const minTagLength = 3
+
+// stopwords are the common english words, and the parts of urls,
+// that say nothing about what a note is about
+var stopwords = toSet(
+	"a", "about", "above", "after", "again", "against", "all", "also", "am", "an", "and", "any",
+	"are", "as", "at", "be", "because", "been", "before", "being", "below", "between", "both",
+	"but", "by", "can", "could", "did", "do", "does", "doing", "done", "down", "during", "each",
+	"even", "every", "few", "for", "from", "further", "get", "gets", "got", "had", "has", "have",
+	"having", "he", "her", "here", "hers", "herself", "him", "himself", "his", "how", "however",
+	"i", "if", "in", "into", "is", "it", "its", "itself", "just", "like", "make", "many", "may",
+	"me", "might", "more", "most", "much", "must", "my", "myself", "need", "never", "new", "no",
+	"nor", "not", "now", "of", "off", "on", "once", "one", "only", "or", "other", "our", "ours",
+	"ourselves", "out", "over", "own", "same", "see", "she", "should", "so", "some", "still",
+	"such", "than", "that", "the", "their", "theirs", "them", "themselves", "then", "there",
+	"these", "they", "thing", "things", "this", "those", "through", "to", "too", "two", "under",
+	"until", "up", "use", "used", "using", "very", "want", "was", "way", "we", "well", "were",
+	"what", "when", "where", "whether", "which", "while", "who", "whom", "why", "will", "with",
+	"within", "without", "would", "yes", "yet", "you", "your", "yours", "yourself", "yourselves",
+	"http", "https", "www", "com", "org", "html",
+)
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+
+	return set
+}
+
+// tokenize splits text into lowercase words of letters, digits, hyphens
+// and underscores, dropping the stopwords, the short words and the numbers
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	})
+
+	var words []string
+
+	for _, field := range fields {
+		word := strings.Trim(field, "-_")
+
+		if len([]rune(word)) < minTagLength || stopwords[word] || !strings.ContainsFunc(word, unicode.IsLetter) {
+			continue
+		}
+
+		words = append(words, word)
+	}
+
+	return words
+}
+
+// SuggestTags returns up to n of the most frequent meaningful words of the
+// note as candidate tags, leaving out its code and the tags it already has.
+// Words used as often are ordered alphabetically.
+func (s Store) SuggestTags(name string, n int) []string {
+	note, ok := s.notesDictionary[noteKey(name)]
+	if !ok || n <= 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+
+	for _, line := range markdown.New(note.Body(), 0).Lines {
+		if line.Type == markdown.LineTypeCode || line.Type == markdown.LineTypeCodeFence {
+			continue
+		}
+
+		for _, word := range tokenize(line.Content) {
+			if !note.HasTag(word) {
+				counts[word]++
+			}
+		}
+	}
+
+	words := make([]string, 0, len(counts))
+	for word := range counts {
+		words = append(words, word)
+	}
+
+	slices.SortFunc(words, func(a, b string) int {
+		return cmp.Or(counts[b]-counts[a], strings.Compare(a, b))
+	})
+
+	return words[:min(n, len(words))]
+}
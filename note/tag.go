@@ -0,0 +1,114 @@
+package note
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AddTag tags the note, writing the tag into its frontmatter
+func (s *Store) AddTag(name, tag string) error {
+	return s.updateTags(name, func(tags []string) []string {
+		if slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return tags
+		}
+
+		return append(tags, tag)
+	})
+}
+
+// RemoveTag removes the tag from the frontmatter of the note, ignoring case
+func (s *Store) RemoveTag(name, tag string) error {
+	return s.updateTags(name, func(tags []string) []string {
+		return slices.DeleteFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) })
+	})
+}
+
+func (s *Store) updateTags(name string, update func(tags []string) []string) error {
+	note, ok := s.notesDictionary[noteKey(name)]
+	if !ok {
+		return fmt.Errorf("note %q not found", name)
+	}
+
+	tags := update(slices.Clone(note.Tags))
+	if slices.Equal(tags, note.Tags) {
+		return nil
+	}
+
+	content, err := setFrontmatterTags(note.Content, tags)
+	if err != nil {
+		return fmt.Errorf("failed to tag %s: %w", name, err)
+	}
+
+	return s.updateNoteContent(note, content)
+}
+
+// setFrontmatterTags replaces the tags declared in the frontmatter of the
+// content, keeping its other keys, and adds the frontmatter when it's missing
+func setFrontmatterTags(content string, tags []string) (string, error) {
+	block, body, ok := splitFrontmatter(content)
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(block), &doc); err != nil {
+		return "", err
+	}
+
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode}},
+		}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return "", errors.New("the frontmatter is not a mapping")
+	}
+
+	var value yaml.Node
+	if err := value.Encode(tags); err != nil {
+		return "", err
+	}
+
+	// keys and values alternate in the content of a mapping
+	index := -1
+	for i := 0; i < len(root.Content); i += 2 {
+		if root.Content[i].Value == "tags" {
+			index = i
+			break
+		}
+	}
+
+	switch {
+	case index >= 0 && len(tags) == 0:
+		root.Content = slices.Delete(root.Content, index, index+2)
+	case index >= 0:
+		// keep tags: [a, b] on one line when they were written so
+		value.Style = root.Content[index+1].Style
+		root.Content[index+1] = &value
+	case len(tags) > 0:
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "tags"}, &value)
+	}
+
+	if len(root.Content) == 0 {
+		return strings.TrimLeft(body, "\n"), nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&doc); err != nil {
+		return "", err
+	}
+
+	if !ok {
+		body = "\n" + body
+	}
+
+	return frontmatterDelimiter + "\n" + buf.String() + frontmatterDelimiter + "\n" + body, nil
+}
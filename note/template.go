@@ -0,0 +1,165 @@
+package note
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const templatesDir = ".templates"
+
+const (
+	cursorPlaceholder    = "{{cursor}}"
+	selectionPlaceholder = "{{selection}}"
+)
+
+// GetTemplate returns the content of the template with the given name
+// from the templates directory of the storage
+func (s Store) GetTemplate(name string) (string, error) {
+	data, err := s.readNote(s.getTemplatePath(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// ListTemplates returns the names of the templates in the templates
+// directory of the storage, sorted alphabetically
+func (s Store) ListTemplates() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.storage, templatesDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	var names []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), s.extension) {
+			names = append(names, strings.TrimSuffix(entry.Name(), s.extension))
+		}
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// CreateFromTemplate creates a note seeded from the given template, or an empty
+// note when no template is given. An existing note with the same name is left
+// untouched, so calling it again never overwrites edits. It reports whether
+// the note was created and makes it the current note either way.
+// It also reports the creation along with an ErrAutoCommit error.
+func (s *Store) CreateFromTemplate(name, templateName string) (bool, error) {
+	if _, err := os.Stat(s.GetNotePath(name)); err == nil {
+		s.currentNoteName = name
+		return false, nil
+	} else if !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to check note %s: %w", name, err)
+	}
+
+	var content string
+
+	if templateName != "" {
+		template, err := s.GetTemplate(templateName)
+		if err != nil {
+			return false, err
+		}
+
+		content, _, _ = ExpandTemplate(template, "")
+	}
+
+	now := time.Now()
+
+	if err := s.saveNote(name, Note{Name: name, Content: s.expandTabs(content), CreatedAt: now, UpdatedAt: now}); err != nil {
+		return false, err
+	}
+
+	s.currentNoteName = name
+
+	return true, s.commit("create " + name)
+}
+
+// SaveAsTemplate copies the note into the templates directory and returns
+// the name of the new template, which gets a suffix if the name is taken.
+// Templates aren't nested, so a note in a folder keeps only its base name.
+func (s *Store) SaveAsTemplate(noteName string) (string, error) {
+	note, ok := s.notesDictionary[noteKey(noteName)]
+	if !ok {
+		return "", fmt.Errorf("note %s not found", noteName)
+	}
+
+	if err := os.MkdirAll(filepath.Join(s.storage, templatesDir), 0755); err != nil {
+		return "", fmt.Errorf("failed to create templates directory: %w", err)
+	}
+
+	name := uniqueName(path.Base(note.Name), s.HasTemplate)
+
+	data, err := s.sealNote(note.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt template: %w", err)
+	}
+
+	if err := writeFileAtomic(s.getTemplatePath(name), data); err != nil {
+		return "", fmt.Errorf("failed to write template file: %w", err)
+	}
+
+	return name, nil
+}
+
+// CreateNoteFromTemplate creates a note named after the template with its
+// placeholders expanded and returns the name of the note, which gets
+// a suffix if the name is taken. The name is also returned along with
+// an ErrAutoCommit error, since the note was created.
+func (s *Store) CreateNoteFromTemplate(templateName string) (string, error) {
+	template, err := s.GetTemplate(templateName)
+	if err != nil {
+		return "", err
+	}
+
+	content, _, _ := ExpandTemplate(template, "")
+
+	err = s.Create(templateName, content)
+	if err != nil && !errors.Is(err, ErrAutoCommit) {
+		return "", err
+	}
+
+	return s.currentNoteName, err
+}
+
+// HasTemplate reports whether a template with the given name exists
+func (s Store) HasTemplate(name string) bool {
+	_, err := os.Stat(s.getTemplatePath(name))
+	return err == nil
+}
+
+func (s Store) getTemplatePath(name string) string {
+	return filepath.Join(s.storage, templatesDir, name+s.extension)
+}
+
+// ExpandTemplate replaces the template placeholders and returns the expanded content
+// along with the position marked by {{cursor}}, falling back to the top of the file
+func ExpandTemplate(content, selection string) (expanded string, row, col int) {
+	content = strings.ReplaceAll(content, selectionPlaceholder, selection)
+
+	index := strings.Index(content, cursorPlaceholder)
+	if index == -1 {
+		return content, 0, 0
+	}
+
+	before := content[:index]
+	row = strings.Count(before, "\n")
+	col = utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:])
+
+	expanded = before + strings.ReplaceAll(content[index+len(cursorPlaceholder):], cursorPlaceholder, "")
+
+	return expanded, row, col
+}
@@ -0,0 +1,191 @@
+package note
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const trashDir = ".trash"
+
+func (s Store) getTrashPath(name string) string {
+	return filepath.Join(s.storage, trashDir, name+s.extension)
+}
+
+// moveToTrash moves the note file into the trash directory, keeping its
+// name unless the trash already holds a note with that name, and returns
+// the name it is kept under
+func (s Store) moveToTrash(path string) (string, error) {
+	return s.moveInto(trashDir, path)
+}
+
+// moveInto moves the note file into one of the hidden directories of the
+// storage, under the same folders as among the notes so that a note such as
+// work/todo keeps its name. It gets a suffix if the directory already holds it,
+// so the name it is kept under is returned.
+func (s Store) moveInto(dir, path string) (string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+
+	notePath := func(name string) string {
+		return filepath.Join(s.storage, dir, name+s.extension)
+	}
+
+	name := uniqueName(s.nameOf(path), func(name string) bool {
+		_, err := os.Stat(notePath(name))
+		return err == nil
+	})
+
+	if err := os.MkdirAll(filepath.Dir(notePath(name)), 0755); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(path, notePath(name)); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// ListTrash returns the deleted notes, most recently deleted first
+func (s *Store) ListTrash() []Note {
+	return s.listNotesIn(trashDir)
+}
+
+// listNotesIn loads the notes kept in one of the hidden directories
+// of the storage, most recently modified first. They are named after
+// their path in the directory, as they were among the notes.
+func (s *Store) listNotesIn(dir string) []Note {
+	root := filepath.Join(s.storage, dir)
+
+	var notes []Note
+
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), s.extension) {
+			return nil
+		}
+
+		note, err := s.loadNoteFromFile(path)
+		if err != nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+
+		note.Name = strings.TrimSuffix(filepath.ToSlash(rel), s.extension)
+		note.Folder = strings.TrimSuffix(filepath.ToSlash(filepath.Dir(rel)), ".")
+		notes = append(notes, note)
+
+		return nil
+	})
+
+	slices.SortStableFunc(notes, compareByUpdatedAt)
+
+	return notes
+}
+
+// Restore moves a note out of the trash and makes it the current note.
+// It gets a suffix if a note with the same name was created since.
+func (s *Store) Restore(name string) error {
+	trashPath := s.getTrashPath(name)
+
+	if _, err := os.Stat(trashPath); err != nil {
+		return fmt.Errorf("failed to find %s in the trash: %w", name, err)
+	}
+
+	if err := s.moveBack(trashPath, name); err != nil {
+		return fmt.Errorf("failed to restore note %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Undelete moves the note deleted last under the given name back out of
+// the trash, as Restore does, pinned again if it was. It's meant for undoing
+// a delete, while the trash may hold older notes with the same name.
+func (s *Store) Undelete(name string) error {
+	trashed, ok := s.trashed[noteKey(name)]
+	if !ok {
+		return fmt.Errorf("%s wasn't deleted", name)
+	}
+
+	if err := s.moveBack(s.getTrashPath(trashed.name), name); err != nil {
+		return fmt.Errorf("failed to restore note %s: %w", name, err)
+	}
+
+	delete(s.trashed, noteKey(name))
+
+	if trashed.pinned {
+		return s.TogglePin(s.currentNoteName)
+	}
+
+	return nil
+}
+
+// moveBack moves a note file from one of the hidden directories back
+// among the notes, into its folder, and makes it the current note.
+// It gets a suffix if a note with the same name was created since.
+func (s *Store) moveBack(path, name string) error {
+	restoredName := s.generateUniqueName(name)
+
+	if err := checkNoteName(restoredName); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.GetNotePath(restoredName)), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(path, s.GetNotePath(restoredName)); err != nil {
+		return err
+	}
+
+	s.removeEmptyFolders(path)
+
+	note, err := s.loadNoteFromFile(s.GetNotePath(restoredName))
+	if err != nil {
+		return err
+	}
+
+	note.Pinned = s.loadPinned()[noteKey(note.Name)]
+
+	s.notesDictionary[noteKey(note.Name)] = note
+	s.notes = append(s.notes, note)
+	slices.SortStableFunc(s.notes, compareByUpdatedAt)
+	s.indexAliases()
+
+	s.currentNoteName = restoredName
+
+	return nil
+}
+
+// removeEmptyFolders removes the folders a note was kept under in one of the
+// hidden directories once they are empty, keeping the hidden directory itself
+func (s Store) removeEmptyFolders(path string) {
+	rel, err := filepath.Rel(s.storage, filepath.Dir(path))
+	if err != nil {
+		return
+	}
+
+	for dir := filepath.ToSlash(rel); strings.Contains(dir, "/"); dir = filepath.ToSlash(filepath.Dir(dir)) {
+		if err := os.Remove(filepath.Join(s.storage, dir)); err != nil {
+			return
+		}
+	}
+}
+
+// EmptyTrash permanently deletes every note in the trash
+func (s *Store) EmptyTrash() error {
+	if err := os.RemoveAll(filepath.Join(s.storage, trashDir)); err != nil {
+		return fmt.Errorf("failed to empty the trash: %w", err)
+	}
+
+	return nil
+}
@@ -3,8 +3,14 @@ package styles
 import (
 	catppuccin "github.com/catppuccin/go"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
+// DisableColor renders every style as plain text, without ANSI escape sequences
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
 var (
 	Base = lipgloss.NewStyle().
 		Foreground(lipgloss.AdaptiveColor{Light: catppuccin.Latte.Base().Hex, Dark: catppuccin.Mocha.Base().Hex})
@@ -0,0 +1,83 @@
+package styles
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var hexColorRegex = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// paletteColors maps the names accepted in a palette file
+// to the style they override
+var paletteColors = map[string]*lipgloss.Style{
+	"base":      &Base,
+	"text":      &Text,
+	"primary":   &Primary,
+	"accent":    &Accent,
+	"success":   &Success,
+	"error":     &Error,
+	"warning":   &Warning,
+	"info":      &Info,
+	"highlight": &Highlight,
+	"subtext0":  &Subtext0,
+	"subtext1":  &Subtext1,
+	"overlay0":  &Overlay0,
+	"overlay1":  &Overlay1,
+	"surface0":  &Surface0,
+	"surface1":  &Surface1,
+	"crust":     &Crust,
+}
+
+// backgroundColors lists the styles whose palette colour is their background
+var backgroundColors = map[string]bool{
+	"highlight": true,
+	"surface0":  true,
+	"surface1":  true,
+	"crust":     true,
+}
+
+// ApplyPalette overrides the named colours (Primary, Accent, Error...) with
+// the given hex or ANSI values. Unknown names and invalid colours keep their
+// defaults and are returned as warnings.
+func ApplyPalette(palette map[string]string) []error {
+	var warnings []error
+
+	for name, value := range palette {
+		key := strings.ToLower(name)
+
+		style, ok := paletteColors[key]
+		if !ok {
+			warnings = append(warnings, fmt.Errorf("unknown palette color %q", name))
+			continue
+		}
+
+		if !isValidColor(value) {
+			warnings = append(warnings, fmt.Errorf("invalid value %q for palette color %q, using the default", value, name))
+			continue
+		}
+
+		color := lipgloss.Color(value)
+
+		if backgroundColors[key] {
+			*style = style.Background(color)
+		} else {
+			*style = style.Foreground(color)
+		}
+	}
+
+	return warnings
+}
+
+// isValidColor reports whether value is a hex colour or an ANSI colour number
+func isValidColor(value string) bool {
+	if hexColorRegex.MatchString(value) {
+		return true
+	}
+
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 0 && n <= 255
+}
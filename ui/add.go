@@ -3,8 +3,8 @@ package ui
 import (
 	"os"
 	"os/exec"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -13,6 +13,7 @@ import (
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	editor "github.com/ionut-t/goeditor/adapter-bubbletea"
+	"github.com/ionut-t/notes/internal/config"
 	"github.com/ionut-t/notes/internal/help"
 	"github.com/ionut-t/notes/internal/keymap"
 	"github.com/ionut-t/notes/internal/utils"
@@ -23,7 +24,8 @@ import (
 type addView int
 
 const (
-	addContent addView = iota
+	addTemplate addView = iota
+	addContent
 	addName
 	abbortAdd
 )
@@ -41,6 +43,7 @@ type AddModel struct {
 	success          bool
 	view             addView
 	editor           editor.Model
+	templatePicker   *huh.Select[string]
 	filename         *huh.Input
 	confirmation     *huh.Confirm
 	filenameError    error
@@ -92,11 +95,79 @@ func NewAddModel(store *note.Store) AddModel {
 		active:       true,
 	}
 
+	if templates, err := store.ListTemplates(); err != nil {
+		m.err = err
+	} else if len(templates) > 0 {
+		m.templatePicker = newTemplatePicker(templates)
+		m.view = addTemplate
+		m.editor.Blur()
+	}
+
 	m.setHelp()
 
 	return m
 }
 
+// newTemplatePicker creates the select offering the templates
+// to start the note from, with a blank note as the first option
+func newTemplatePicker(templates []string) *huh.Select[string] {
+	options := []huh.Option[string]{huh.NewOption("blank", "")}
+	for _, name := range templates {
+		options = append(options, huh.NewOption(name, name))
+	}
+
+	picker := huh.NewSelect[string]().
+		Title("Template").
+		Options(options...)
+
+	picker.WithKeyMap(&huh.KeyMap{
+		Select: huh.NewDefaultKeyMap().Select,
+	})
+
+	picker.WithTheme(styles.ThemeCatppuccin())
+	picker.Focus()
+
+	return picker
+}
+
+// chooseTemplate pre-fills the editor with the picked template
+// and moves on to writing the note
+func (m *AddModel) chooseTemplate() {
+	if name := m.templatePicker.GetValue().(string); name != "" {
+		template, err := m.store.GetTemplate(name)
+		if err != nil {
+			m.err = err
+			return
+		}
+
+		m.SetTemplate(template)
+	}
+
+	m.templatePicker.Blur()
+	m.view = addContent
+	m.setHelp()
+	m.editor.Focus()
+}
+
+// SetTemplate pre-fills the editor with the given template, placing
+// the cursor where the template's {{cursor}} marker was. The template
+// picker is skipped.
+func (m *AddModel) SetTemplate(template string) {
+	content, row, col := note.ExpandTemplate(template, "")
+
+	if m.view == addTemplate {
+		m.view = addContent
+		m.setHelp()
+		m.editor.Focus()
+	}
+
+	m.editor.SetContent(content)
+
+	if err := m.editor.SetCursorPosition(row, col); err != nil {
+		m.err = err
+	}
+}
+
 func (m *AddModel) markAsIntegrated() {
 	m.standalone = false
 	m.setContentHeight()
@@ -199,6 +270,10 @@ func (m AddModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			return m, execCmd
 
+		case key.Matches(msg, keymap.ChooseTemplate) && m.view == addTemplate && !m.templatePicker.GetFiltering():
+			m.chooseTemplate()
+			return m, m.editor.CursorBlink()
+
 		case key.Matches(msg, keymap.Save):
 			if m.showConfirmation {
 				confirmed := m.confirmation.GetValue().(bool)
@@ -236,7 +311,7 @@ func (m AddModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				noteName = strings.Join(strings.Split(noteName, " "), "-")
 
-				if err := m.store.Create(noteName, content); err != nil {
+				if err := m.store.Create(noteName, content); err != nil && !commitFailed(err) {
 					m.err = err
 
 					if !m.standalone {
@@ -247,10 +322,15 @@ func (m AddModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 					if m.standalone {
 						m.success = true
+						m.err = err
 
 						return m, tea.Quit
 					}
 
+					if err != nil {
+						return m, tea.Sequence(dispatch(noteAddedMsg{}), dispatch(cmdErrorMsg(err)))
+					}
+
 					return m, dispatch(noteAddedMsg{})
 				}
 			}
@@ -281,6 +361,11 @@ func (m AddModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	switch m.view {
+	case addTemplate:
+		picker, cmd := m.templatePicker.Update(msg)
+		m.templatePicker = picker.(*huh.Select[string])
+		cmds = append(cmds, cmd)
+
 	case addContent:
 		content, cmd := m.editor.Update(msg)
 		m.editor = content.(editor.Model)
@@ -336,6 +421,8 @@ func (m AddModel) getView() string {
 	footer := utils.Ternary(m.showConfirmation, m.confirmation.View(), m.help.View())
 
 	switch m.view {
+	case addTemplate:
+		return m.templatePicker.View() + "\n\n" + footer
 	case addContent:
 		return m.editor.View() + "\n\n" + footer
 	case addName:
@@ -351,6 +438,14 @@ func (m AddModel) getView() string {
 
 func (m *AddModel) setHelp() {
 	switch m.view {
+	case addTemplate:
+		m.help.Keys.ShortHelpBindings = []key.Binding{
+			keymap.Up,
+			keymap.Down,
+			keymap.ChooseTemplate,
+			keymap.Back,
+		}
+
 	case addContent:
 		if m.standalone {
 			m.help.Keys.ShortHelpBindings = []key.Binding{
@@ -382,19 +477,17 @@ func (m *AddModel) setName() {
 		return
 	}
 
-	content := m.editor.GetCurrentContent()
-	name := strings.Split(content, "\n")[0]
+	title, ok := note.NameFromHeader(m.editor.GetCurrentContent())
 
-	if strings.HasPrefix(name, "#") {
-		name = strings.Trim(name, "#")
-		name = strings.TrimSpace(name)
-		// Replace any sequence of dashes, spaces, or # with a single space
-		re := regexp.MustCompile(`[#\s-]+`)
-		name = re.ReplaceAllString(name, " ")
-		name = strings.Join(strings.Fields(name), "-")
-		name = strings.ToLower(name)
+	if template := config.GetNameTemplate(); template != "" {
+		if name := note.ExpandNameTemplate(template, title, time.Now()); name != "" {
+			m.filename.Value(&name)
+			return
+		}
+	}
 
-		m.filename.Value(&name)
+	if ok {
+		m.filename.Value(&title)
 	}
 }
 
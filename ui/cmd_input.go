@@ -0,0 +1,261 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ionut-t/notes/internal/config"
+	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/internal/utils"
+	"github.com/ionut-t/notes/note"
+	"github.com/ionut-t/notes/styles"
+)
+
+type cmdResetMsg struct{}
+
+type cmdRandomMsg struct{}
+
+type cmdToTemplateMsg struct{}
+
+type cmdArchiveMsg struct{}
+
+// cmdSuggestTagsMsg shows the words of the current note suggested as tags
+type cmdSuggestTagsMsg struct{}
+
+// cmdDiffMsg shows what the last external edit changed
+type cmdDiffMsg struct{}
+
+// cmdProfileMsg switches to the notes of another profile
+type cmdProfileMsg struct {
+	name string
+}
+
+// cmdGotoMsg selects the nth note of the list, counting from 1
+type cmdGotoMsg struct {
+	n int
+}
+
+type cmdMergeMsg struct {
+	source string
+}
+
+// cmdTagMsg adds the tag to the selected notes, or removes it
+type cmdTagMsg struct {
+	tag    string
+	remove bool
+}
+
+type cmdSetThemeMsg struct {
+	theme string
+}
+
+type cmdFromTemplateMsg struct {
+	template string
+}
+
+// cmdExtractMsg creates a note from a range of lines of the current note
+type cmdExtractMsg struct {
+	start, end int
+	name       string
+}
+
+// cmdInputModel is the command prompt opened with ":" from the notes list
+type cmdInputModel struct {
+	store  *note.Store
+	input  textinput.Model
+	active bool
+}
+
+func newCmdInputModel(store *note.Store) cmdInputModel {
+	input := textinput.New()
+	input.Prompt = ":"
+	input.PromptStyle = styles.Accent
+	input.Cursor.Style = styles.Accent
+
+	return cmdInputModel{
+		store: store,
+		input: input,
+	}
+}
+
+func (m *cmdInputModel) open() tea.Cmd {
+	m.active = true
+	m.input.Reset()
+	return m.input.Focus()
+}
+
+func (m *cmdInputModel) close() {
+	m.active = false
+	m.input.Blur()
+	m.input.Reset()
+}
+
+func (m cmdInputModel) Update(msg tea.Msg) (cmdInputModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keymap.Cancel):
+			m.close()
+			return m, nil
+
+		case key.Matches(msg, keymap.RunCommand):
+			value := m.input.Value()
+			m.close()
+			return m, m.handleCmdRunner(value)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+
+	return m, cmd
+}
+
+func (m cmdInputModel) View() string {
+	return m.input.View()
+}
+
+// handleCmdRunner parses the command line and returns the command that executes it
+func (m cmdInputModel) handleCmdRunner(value string) tea.Cmd {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	command := fields[0]
+
+	switch command {
+	case "reset":
+		return dispatch(cmdResetMsg{})
+
+	case "random":
+		return dispatch(cmdRandomMsg{})
+
+	case "set-theme":
+		if len(fields) != 2 {
+			return dispatch(cmdErrorMsg(errors.New("usage: set-theme <name>")))
+		}
+
+		return dispatch(cmdSetThemeMsg{theme: fields[1]})
+
+	case "archive":
+		return dispatch(cmdArchiveMsg{})
+
+	case "diff":
+		return dispatch(cmdDiffMsg{})
+
+	case "suggest-tags":
+		return dispatch(cmdSuggestTagsMsg{})
+
+	case "editor-once":
+		if len(fields) != 2 {
+			return dispatch(cmdErrorMsg(errors.New("usage: editor-once <editor>")))
+		}
+
+		if err := m.store.UseEditorOnce(fields[1]); err != nil {
+			return dispatch(cmdErrorMsg(err))
+		}
+
+		return dispatch(cmdSuccessMsg(fmt.Sprintf("Using %s as the editor until the notes are closed", fields[1])))
+
+	case "to-template":
+		return dispatch(cmdToTemplateMsg{})
+
+	case "yank":
+		if len(fields) < 2 {
+			return dispatch(cmdErrorMsg(errors.New("usage: yank <name> [name...]")))
+		}
+
+		names := fields[1:]
+		if err := m.store.CopyNotes(names); err != nil {
+			return dispatch(cmdErrorMsg(err))
+		}
+
+		return dispatch(cmdSuccessMsg(fmt.Sprintf("Copied %d %s to the clipboard", len(names), utils.Ternary(len(names) == 1, "note", "notes"))))
+
+	case "copyblock":
+		if len(fields) != 2 {
+			return dispatch(cmdErrorMsg(errors.New("usage: copyblock <n>")))
+		}
+
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return dispatch(cmdErrorMsg(fmt.Errorf("invalid code block number: %s", fields[1])))
+		}
+
+		block, err := m.store.CopyCodeBlock(n)
+		if err != nil {
+			return dispatch(cmdErrorMsg(err))
+		}
+
+		return dispatch(cmdSuccessMsg(fmt.Sprintf("Copied code block %d%s to the clipboard", n, utils.Ternary(block.Language == "", "", " ("+block.Language+")"))))
+
+	case "profile":
+		if len(fields) != 2 {
+			profiles := slices.Sorted(maps.Keys(config.GetProfiles()))
+			return dispatch(cmdErrorMsg(fmt.Errorf("usage: profile <name>, one of: %s", strings.Join(profiles, ", "))))
+		}
+
+		return dispatch(cmdProfileMsg{name: fields[1]})
+
+	case "goto":
+		if len(fields) != 2 {
+			return dispatch(cmdErrorMsg(errors.New("usage: goto <n>")))
+		}
+
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return dispatch(cmdErrorMsg(fmt.Errorf("invalid note number: %s", fields[1])))
+		}
+
+		return dispatch(cmdGotoMsg{n: n})
+
+	case "tag", "untag":
+		if len(fields) != 2 {
+			return dispatch(cmdErrorMsg(fmt.Errorf("usage: %s <tag>", command)))
+		}
+
+		return dispatch(cmdTagMsg{tag: strings.TrimPrefix(fields[1], "#"), remove: command == "untag"})
+
+	case "merge":
+		if len(fields) < 2 {
+			return dispatch(cmdErrorMsg(errors.New("usage: merge <name>")))
+		}
+
+		return dispatch(cmdMergeMsg{source: strings.Join(fields[1:], " ")})
+
+	case "extract":
+		if len(fields) < 4 {
+			return dispatch(cmdErrorMsg(errors.New("usage: extract <start> <end> <name>")))
+		}
+
+		start, startErr := strconv.Atoi(fields[1])
+		end, endErr := strconv.Atoi(fields[2])
+		if startErr != nil || endErr != nil {
+			return dispatch(cmdErrorMsg(fmt.Errorf("invalid line range: %s %s", fields[1], fields[2])))
+		}
+
+		name := strings.Join(fields[3:], " ")
+		if len(name) > maxNoteNameLength {
+			return dispatch(cmdErrorMsg(fmt.Errorf("name cannot be longer than %d characters", maxNoteNameLength)))
+		}
+
+		return dispatch(cmdExtractMsg{start: start, end: end, name: name})
+
+	case "from-template":
+		if len(fields) < 2 {
+			return dispatch(cmdErrorMsg(errors.New("usage: from-template <name>")))
+		}
+
+		return dispatch(cmdFromTemplateMsg{template: strings.Join(fields[1:], " ")})
+
+	default:
+		return dispatch(cmdErrorMsg(fmt.Errorf("unknown command: %s", command)))
+	}
+}
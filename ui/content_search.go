@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/styles"
+)
+
+// snippetRadius is how much context is shown around a match in the list
+const snippetRadius = 30
+
+// contentSearchModel is the prompt narrowing the list to the notes
+// whose content matches the query
+type contentSearchModel struct {
+	input  textinput.Model
+	active bool
+}
+
+func newContentSearchModel() contentSearchModel {
+	input := textinput.New()
+	input.Prompt = "Search contents: "
+	input.PromptStyle = styles.Accent
+	input.Cursor.Style = styles.Accent
+
+	return contentSearchModel{
+		input: input,
+	}
+}
+
+// open starts editing the query, keeping the current one
+func (m *contentSearchModel) open() tea.Cmd {
+	m.active = true
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+// close stops editing the query, which stays applied
+func (m *contentSearchModel) close() {
+	m.active = false
+	m.input.Blur()
+}
+
+// clear removes the query and closes the prompt
+func (m *contentSearchModel) clear() {
+	m.input.Reset()
+	m.close()
+}
+
+func (m contentSearchModel) query() string {
+	return strings.TrimSpace(m.input.Value())
+}
+
+func (m contentSearchModel) Update(msg tea.Msg) (contentSearchModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keymap.Cancel):
+			m.clear()
+			return m, nil
+
+		case key.Matches(msg, keymap.RunCommand):
+			m.close()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+
+	return m, cmd
+}
+
+func (m contentSearchModel) View() string {
+	return m.input.View()
+}
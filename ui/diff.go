@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/note"
+	"github.com/ionut-t/notes/styles"
+)
+
+// diffLine is a line of a diff with the kind of change it's part of
+type diffLine struct {
+	kind note.DiffKind
+	text string
+}
+
+// diffModel shows the lines changed by the last edit of a note
+// in the external editor, scrolled a line at a time
+type diffModel struct {
+	name     string
+	lines    []diffLine
+	inserted int
+	deleted  int
+	offset   int
+	active   bool
+}
+
+// set keeps the changes the external editor made to the note until it's used again
+func (m *diffModel) set(name string, ops []note.DiffOp) {
+	m.name = name
+	m.lines = nil
+
+	for _, op := range ops {
+		for line := range strings.Lines(op.Text) {
+			m.lines = append(m.lines, diffLine{kind: op.Kind, text: strings.TrimSuffix(line, "\n")})
+		}
+	}
+
+	m.inserted, m.deleted = note.LineChanges(ops)
+}
+
+// open shows the last changes, reporting whether there are any
+func (m *diffModel) open() bool {
+	m.offset = 0
+	m.active = len(m.lines) > 0
+
+	return m.active
+}
+
+func (m *diffModel) close() {
+	m.active = false
+}
+
+func (m diffModel) Update(msg tea.Msg) (diffModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, keymap.Cancel, keymap.RunCommand):
+		m.close()
+
+	case key.Matches(keyMsg, keymap.Up):
+		m.offset = max(m.offset-1, 0)
+
+	case key.Matches(keyMsg, keymap.Down):
+		m.offset = min(m.offset+1, max(len(m.lines)-1, 0))
+	}
+
+	return m, nil
+}
+
+// View renders the lines of the diff from the scroll offset,
+// marking the inserted and deleted ones
+func (m diffModel) View(width, height int) string {
+	title := styles.Accent.Bold(true).Render("Changes to "+m.name) + "  " +
+		styles.Success.Render(fmt.Sprintf("+%d", m.inserted)) + " " +
+		styles.Error.Render(fmt.Sprintf("-%d", m.deleted))
+
+	lines := []string{title}
+	end := min(len(m.lines), m.offset+max(height-1, 1))
+
+	for _, line := range m.lines[m.offset:end] {
+		switch line.kind {
+		case note.DiffInsert:
+			lines = append(lines, styles.Success.MaxWidth(width).Render("+ "+line.text))
+		case note.DiffDelete:
+			lines = append(lines, styles.Error.MaxWidth(width).Render("- "+line.text))
+		default:
+			lines = append(lines, styles.Subtext0.MaxWidth(width).Render("  "+line.text))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/styles"
+)
+
+// openLinkMsg asks the manager to open the linked note
+type openLinkMsg struct {
+	name string
+}
+
+// linksModel lists the notes linked from the current note with [[name]],
+// followed by the notes linking back to it, to open one of them
+type linksModel struct {
+	links     []string
+	backlinks []string
+	cursor    int
+	active    bool
+}
+
+func (m *linksModel) open(links, backlinks []string) {
+	m.links = links
+	m.backlinks = backlinks
+	m.cursor = 0
+	m.active = true
+}
+
+func (m *linksModel) close() {
+	m.active = false
+}
+
+// selected returns the name under the cursor, going through the links
+// before the backlinks
+func (m linksModel) selected() (string, bool) {
+	switch {
+	case m.cursor < len(m.links):
+		return m.links[m.cursor], true
+	case m.cursor-len(m.links) < len(m.backlinks):
+		return m.backlinks[m.cursor-len(m.links)], true
+	default:
+		return "", false
+	}
+}
+
+func (m linksModel) Update(msg tea.Msg) (linksModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, keymap.Cancel, keymap.Links):
+		m.close()
+
+	case key.Matches(keyMsg, keymap.Up):
+		m.cursor = max(m.cursor-1, 0)
+
+	case key.Matches(keyMsg, keymap.Down):
+		m.cursor = max(min(m.cursor+1, len(m.links)+len(m.backlinks)-1), 0)
+
+	case key.Matches(keyMsg, keymap.RunCommand):
+		m.close()
+
+		if name, ok := m.selected(); ok {
+			return m, dispatch(openLinkMsg{name: name})
+		}
+	}
+
+	return m, nil
+}
+
+// View renders both lists, scrolled to keep the cursor in view
+func (m linksModel) View(width, height int) string {
+	if len(m.links) == 0 && len(m.backlinks) == 0 {
+		return styles.Subtext0.Render("This note has no links and no backlinks")
+	}
+
+	var lines []string
+	cursorLine := 0
+
+	section := func(title string, names []string, offset int) {
+		lines = append(lines, styles.Accent.Bold(true).Render(title))
+
+		if len(names) == 0 {
+			lines = append(lines, styles.Subtext0.MaxWidth(width).Render("  None"))
+		}
+
+		for i, name := range names {
+			if offset+i == m.cursor {
+				cursorLine = len(lines)
+				lines = append(lines, styles.Primary.Bold(true).MaxWidth(width).Render("> "+name))
+			} else {
+				lines = append(lines, styles.Text.MaxWidth(width).Render("  "+name))
+			}
+		}
+	}
+
+	section("Links", m.links, 0)
+	lines = append(lines, "")
+	section("Backlinks", m.backlinks, len(m.links))
+
+	height = max(height, 1)
+	start := max(0, cursorLine-height+1)
+	end := min(len(lines), start+height)
+
+	return strings.Join(lines[start:end], "\n")
+}
@@ -1,34 +1,57 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"os"
 	"os/exec"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	editor "github.com/ionut-t/goeditor/adapter-bubbletea"
+	"github.com/ionut-t/notes/internal/config"
 	"github.com/ionut-t/notes/internal/help"
 	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/internal/state"
+	"github.com/ionut-t/notes/internal/utils"
 	"github.com/ionut-t/notes/note"
 	"github.com/ionut-t/notes/styles"
 )
 
 var (
-	viewPadding  = lipgloss.NewStyle().Padding(1, 1)
-	activeBorder = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(styles.Text.GetForeground())
-	inactiveBorder = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(styles.Overlay0.
-				GetForeground())
+	viewPadding             = lipgloss.NewStyle().Padding(1, 1)
+	paneBorder              = lipgloss.NewStyle().Border(lipgloss.RoundedBorder())
 	splitViewSeparator      = " "
 	splitViewSeparatorWidth = lipgloss.Width(splitViewSeparator)
-	minListWidth            = 50
 )
 
+const (
+	// splitRatioStep is how much "<" and ">" resize the list pane by
+	splitRatioStep = 0.05
+	minSplitRatio  = 0.15
+	maxSplitRatio  = 0.85
+)
+
+// activeBorder and inactiveBorder are built on demand so that
+// they pick up the colours of a custom palette
+func activeBorder() lipgloss.Style {
+	return paneBorder.BorderForeground(styles.Text.GetForeground())
+}
+
+func inactiveBorder() lipgloss.Style {
+	return paneBorder.BorderForeground(styles.Overlay0.GetForeground())
+}
+
+// todoItem is the task line inserted by the append todo binding
+const todoItem = "- [ ] "
+
 type managerView int
 
 const (
@@ -55,6 +78,30 @@ type ManagerModel struct {
 	width, height  int
 	successMessage string
 	addNote        AddModel
+	minListWidth   int
+	splitRatio     float64 // fraction of the split view taken by the list, 0 to keep minListWidth
+	compactList    bool
+	cmdInput       cmdInputModel
+	contentSearch  contentSearchModel
+	sortOrder      note.SortOrder
+	lastAction     *undoableAction
+	// content of the note opened in the external editor, to show what the editor changed
+	contentBeforeEditor string
+
+	// names of the current note and of the notes opened before it, most recent first
+	recentNotes []string
+
+	// notes marked for bulk actions, by name
+	selected          map[string]bool
+	confirmBulkDelete bool
+
+	// asks whether to discard the unsaved changes of the note before quitting
+	quitConfirmation     *huh.Confirm
+	showQuitConfirmation bool
+
+	// when folderScoped is set the list only shows the notes in folderScope
+	folderScoped bool
+	folderScope  string
 }
 
 func NewManager(store *note.Store) *ManagerModel {
@@ -63,18 +110,47 @@ func NewManager(store *note.Store) *ManagerModel {
 		notes = []note.Note{}
 	}
 
-	items := processNotes(notes)
+	sortOrder, orderErr := note.ParseSortOrder(config.GetSortOrder())
+	if orderErr != nil {
+		sortOrder = note.SortByModified
+	}
 
-	delegate := list.NewDefaultDelegate()
+	items := processNotes(notes, sortOrder)
 
-	delegate.Styles = styles.ListItemStyles()
+	saved := state.Load()
+
+	// a list resized in a previous session takes precedence over split_ratio
+	splitRatio := config.GetSplitRatio()
+	if saved.SplitRatio >= minSplitRatio && saved.SplitRatio <= maxSplitRatio {
+		splitRatio = saved.SplitRatio
+	}
+
+	quitConfirmation := huh.NewConfirm().
+		Title("You have unsaved changes. Discard them and quit?").
+		Affirmative("Yes").
+		Negative("No").
+		Inline(true)
+
+	quitConfirmation.WithKeyMap(&huh.KeyMap{
+		Confirm: huh.NewDefaultKeyMap().Confirm,
+	})
+
+	quitConfirmation.WithTheme(styles.ThemeCatppuccin())
 
 	m := ManagerModel{
-		store:    store,
-		list:     list.New(items, delegate, 0, 0),
-		help:     help.New(),
-		noteView: NewNoteModel(store, 100, 20),
-		error:    err,
+		store:         store,
+		list:          list.New(items, newListDelegate(false), 0, 0),
+		help:          help.New(),
+		noteView:      NewNoteModel(store, 100, 20),
+		error:         err,
+		minListWidth:  config.GetMinListWidth(),
+		splitRatio:    splitRatio,
+		cmdInput:      newCmdInputModel(store),
+		contentSearch: newContentSearchModel(),
+		sortOrder:     sortOrder,
+		selected:      make(map[string]bool),
+
+		quitConfirmation: quitConfirmation,
 	}
 
 	m.list.Title = "Notes"
@@ -95,35 +171,79 @@ func NewManager(store *note.Store) *ManagerModel {
 	m.list.InfiniteScrolling = true
 	m.list.SetShowHelp(false)
 
-	m.help.Keys.FullHelpBindings = []key.Binding{
-		keymap.Up,
-		keymap.Down,
-		keymap.Left,
-		keymap.Right,
-		keymap.FullScreen,
-		keymap.ExternalEditor,
-		keymap.New,
-		keymap.Search,
-		keymap.Quit,
-		keymap.Help,
+	m.help.Keys.FullHelpBindings = keymap.ManagerBindings
+
+	if current, ok := store.GetCurrentNote(); ok {
+		m.selectNote(current.Name)
+
+		if saved.NoteFocused {
+			m.focusedView = noteFocused
+			m.noteView.focus()
+		}
 	}
 
 	return &m
 }
 
+// newListDelegate creates the list delegate, showing only
+// the note titles when compact is set
+func newListDelegate(compact bool) list.DefaultDelegate {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles = styles.ListItemStyles()
+
+	if compact {
+		delegate.ShowDescription = false
+		delegate.SetSpacing(0)
+	}
+
+	return delegate
+}
+
 type item struct {
 	title, desc string
+	// displayName is the title from the frontmatter, shown instead of the name
+	displayName string
+	pinned      bool
+	selected    bool
+}
+
+func (i item) Title() string {
+	title := i.displayName
+
+	if i.pinned {
+		title = "★ " + title
+	}
+
+	if i.selected {
+		title = "✓ " + title
+	}
+
+	return title
 }
 
-func (i item) Title() string       { return i.title }
 func (i item) Description() string { return i.desc }
-func (i item) FilterValue() string { return i.title }
+func (i item) FilterValue() string { return i.displayName }
 
 func (m ManagerModel) Init() tea.Cmd {
-	return tea.SetWindowTitle("Notes")
+	if m.focusedView == noteFocused {
+		return tea.Batch(tea.SetWindowTitle("Notes"), m.noteView.focus(), autosave(m.noteView.autosaveInterval))
+	}
+
+	return tea.Batch(tea.SetWindowTitle("Notes"), autosave(m.noteView.autosaveInterval))
 }
 
 func (m ManagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+
+	if updated, ok := model.(ManagerModel); ok {
+		updated.trackRecentNote()
+		return updated, cmd
+	}
+
+	return model, cmd
+}
+
+func (m ManagerModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -140,6 +260,8 @@ func (m ManagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleEditorClose(true)
 
 	case cmdNoteDeletedMsg:
+		m.lastAction = &undoableAction{deleted: &msg.note}
+		delete(m.selected, msg.note.Name)
 		m.list.RemoveItem(m.list.Index())
 		if item, ok := m.list.SelectedItem().(item); ok {
 			m.store.SetCurrentNoteName(item.title)
@@ -163,11 +285,12 @@ func (m ManagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		)
 
 	case cmdNoteRenamedMsg:
-		note := msg.note
-		m.list.SetItem(m.list.Index(), item{
-			title: note.Name,
-			desc:  fmt.Sprintf("Last modified: %s", note.UpdatedAt.Format("02/01/2006 15:04")),
-		})
+		m.lastAction = &undoableAction{previousName: msg.previousName, renamedTo: msg.note.Name}
+		m.renameSelected(msg.previousName, msg.note.Name)
+
+		renamed := newItem(msg.note)
+		renamed.selected = m.selected[msg.note.Name]
+		m.list.SetItem(m.list.Index(), renamed)
 
 	case clearMsg:
 		m.successMessage = ""
@@ -180,33 +303,196 @@ func (m ManagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.focusedView = listFocused
 		}
 
-	case editor.SaveMsg:
-		err := m.store.UpdateCurrentNoteContent(string(msg.Content))
-		if err != nil {
-			m.error = fmt.Errorf("failed to save note: %w", err)
-			m.successMessage = ""
-		} else {
-			m.successMessage = "Note saved"
-			m.error = nil
-			m.noteView.updateContent()
+		if err := m.noteView.discardDraft(); err != nil {
+			return m, dispatch(cmdErrorMsg(err))
+		}
 
-			if m.view == splitView {
-				m.list.SetItems(processNotes(m.store.GetNotes()))
-				m.list.ResetSelected()
+	case autosaveMsg:
+		return m, tea.Batch(m.noteView.saveDraft(), autosave(m.noteView.autosaveInterval))
+
+	case editor.SaveMsg:
+		if note, ok := m.store.GetCurrentNote(); ok {
+			if diskContent, changed := m.store.GetExternalChanges(note.Name); changed {
+				m.noteView.confirmReload(diskContent, msg.Content)
+				break
 			}
+		}
+
+		return m.saveNote(msg.Content)
+
+	case overwriteNoteMsg:
+		return m.saveNote(msg.content)
+
+	case reloadNoteMsg:
+		if _, err := m.store.ReloadCurrentNote(); err != nil {
+			return m, dispatch(cmdErrorMsg(err))
+		}
+
+		m.noteView.updateContent()
+		m.list.SetItems(m.listItems())
+
+		return m, dispatch(cmdSuccessMsg("Note reloaded from disk"))
+
+	case cmdResetMsg:
+		result := dispatch(cmdSuccessMsg("View reset to defaults"))
+		if err := m.reset(); err != nil {
+			result = dispatch(cmdErrorMsg(err))
+		}
+
+		return m, tea.Batch(result, m.dispatchWindowSizeMsg())
+
+	case cmdRandomMsg:
+		return m.openRandomNote()
+
+	case cmdSetThemeMsg:
+		if err := m.noteView.setTheme(msg.theme); err != nil {
+			return m, dispatch(cmdErrorMsg(err))
+		}
+
+		if err := config.SetTheme(msg.theme); err != nil {
+			return m, dispatch(cmdErrorMsg(fmt.Errorf("failed to save theme: %w", err)))
+		}
 
-			return m, dispatchClearMsg()
+		return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Theme set to %s", msg.theme)))
+
+	case cmdToTemplateMsg:
+		return m.saveAsTemplate()
+
+	case cmdArchiveMsg:
+		return m.archiveNote()
+
+	case cmdMergeMsg:
+		return m.mergeNote(msg.source)
+
+	case cmdTagMsg:
+		return m.tagNotes(msg.tag, msg.remove)
+
+	case outlineJumpMsg:
+		m.noteView.jumpTo(msg.line)
+		return m, nil
+
+	case openLinkMsg:
+		return m.openLink(msg.name)
+
+	case cmdFromTemplateMsg:
+		return m.createFromTemplate(msg.template)
+
+	case cmdExtractMsg:
+		return m.extractLines(msg.start, msg.end, msg.name)
+
+	case cmdSuggestTagsMsg:
+		if current, ok := m.store.GetCurrentNote(); ok {
+			m.noteView.tags.open(m.store.SuggestTags(current.Name, maxTagSuggestions))
+		}
+
+		return m, nil
+
+	case addTagsMsg:
+		return m.addTags(msg.tags)
+
+	case cmdProfileMsg:
+		return m.switchProfile(msg.name)
+
+	case cmdGotoMsg:
+		return m.gotoNote(msg.n)
+
+	case cmdDiffMsg:
+		if !m.noteView.diff.open() {
+			return m, dispatch(cmdErrorMsg(errors.New("no note was changed in the external editor")))
 		}
 
+		return m, nil
+
 	case editor.QuitMsg:
-		return m, tea.Quit
+		return m, m.quit()
 
 	case editor.ErrorMsg:
 		return m, m.noteView.dispatchEditorError(msg.Error)
 
 	case tea.KeyMsg:
 		if key.Matches(msg, keymap.ForceQuit) {
-			return m, tea.Quit
+			// pressing it again while asked about the unsaved changes quits anyway
+			if m.noteView.hasChanges() && !m.showQuitConfirmation {
+				return m.confirmQuit()
+			}
+
+			return m, m.quit()
+		}
+
+		if m.showQuitConfirmation {
+			switch {
+			case key.Matches(msg, keymap.Save):
+				m.showQuitConfirmation = false
+
+				if m.quitConfirmation.GetValue().(bool) {
+					_ = m.noteView.discardDraft()
+					return m, m.quit()
+				}
+
+				return m, nil
+
+			case key.Matches(msg, keymap.Cancel):
+				m.showQuitConfirmation = false
+				return m, nil
+			}
+
+			confirmation, cmd := m.quitConfirmation.Update(msg)
+			m.quitConfirmation = confirmation.(*huh.Confirm)
+
+			return m, cmd
+		}
+
+		if m.cmdInput.active {
+			var cmd tea.Cmd
+			m.cmdInput, cmd = m.cmdInput.Update(msg)
+			return m, cmd
+		}
+
+		if m.noteView.outline.active {
+			var cmd tea.Cmd
+			m.noteView.outline, cmd = m.noteView.outline.Update(msg)
+			return m, cmd
+		}
+
+		if m.noteView.links.active {
+			var cmd tea.Cmd
+			m.noteView.links, cmd = m.noteView.links.Update(msg)
+			return m, cmd
+		}
+
+		if m.noteView.tags.active {
+			var cmd tea.Cmd
+			m.noteView.tags, cmd = m.noteView.tags.Update(msg)
+			return m, cmd
+		}
+
+		if m.noteView.diff.active {
+			var cmd tea.Cmd
+			m.noteView.diff, cmd = m.noteView.diff.Update(msg)
+			return m, cmd
+		}
+
+		if m.noteView.recent.active {
+			var cmd tea.Cmd
+			m.noteView.recent, cmd = m.noteView.recent.Update(msg)
+			return m, cmd
+		}
+
+		if m.contentSearch.active {
+			var cmd tea.Cmd
+			m.contentSearch, cmd = m.contentSearch.Update(msg)
+			m.applyContentSearch()
+			return m, cmd
+		}
+
+		if m.confirmBulkDelete {
+			m.confirmBulkDelete = false
+
+			if key.Matches(msg, keymap.Accept) {
+				return m.deleteSelected()
+			}
+
+			return m, nil
 		}
 
 		if m.list.FilterState() == list.Filtering || m.addNote.active {
@@ -214,6 +500,29 @@ func (m ManagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		switch {
+		case key.Matches(msg, keymap.Cancel):
+			if m.focusedView == listFocused && len(m.selected) > 0 {
+				clear(m.selected)
+				m.list.SetItems(m.listItems())
+				return m, nil
+			}
+
+		case key.Matches(msg, keymap.ToggleSelect):
+			if m.focusedView == listFocused {
+				m.toggleSelection()
+				return m, nil
+			}
+
+		case key.Matches(msg, keymap.DeleteSelected):
+			if m.focusedView == listFocused && len(m.selected) > 0 {
+				if !config.GetConfirmDelete() {
+					return m.deleteSelected()
+				}
+
+				m.confirmBulkDelete = true
+				return m, nil
+			}
+
 		case key.Matches(msg, keymap.Quit):
 			return m.handleQuit()
 
@@ -225,6 +534,47 @@ func (m ManagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.noteView.toggleEdit()
 			}
 
+		case key.Matches(msg, keymap.Outline):
+			if !m.noteView.isEditing() && !m.noteView.showEditor {
+				m.noteView.toggleOutline()
+				return m, nil
+			}
+
+		case key.Matches(msg, keymap.Links):
+			if !m.noteView.isEditing() && !m.noteView.showEditor {
+				m.noteView.toggleLinks()
+				return m, nil
+			}
+
+		case key.Matches(msg, keymap.RecentNotes):
+			if m.view != listView && (m.focusedView == listFocused || (!m.noteView.isEditing() && !m.noteView.showEditor)) {
+				m.openRecentNotes()
+				return m, nil
+			}
+
+		case key.Matches(msg, keymap.CopyPlainText):
+			if m.focusedView == listFocused || (!m.noteView.isEditing() && !m.noteView.showEditor) {
+				return m.copyPlainText()
+			}
+
+		case key.Matches(msg, keymap.ToggleSection):
+			if !m.noteView.isEditing() && !m.noteView.showEditor {
+				m.noteView.toggleSection()
+				return m, nil
+			}
+
+		case key.Matches(msg, keymap.VLine):
+			if !m.noteView.isEditing() && !m.noteView.showEditor {
+				m.noteView.toggleLineNumbers()
+				return m, nil
+			}
+
+		case key.Matches(msg, keymap.RevealWhitespace):
+			if !m.noteView.isEditing() && !m.noteView.showEditor {
+				m.noteView.toggleWhitespace()
+				return m, nil
+			}
+
 		case key.Matches(msg, keymap.ExternalEditor):
 			if ok, cmd := m.triggerNoteEditor(); ok {
 				return m, cmd
@@ -247,6 +597,67 @@ func (m ManagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case key.Matches(msg, keymap.ShrinkList, keymap.GrowList):
+			if m.view == splitView && m.focusedView == listFocused {
+				return m.resizeList(utils.Ternary(key.Matches(msg, keymap.ShrinkList), -splitRatioStep, splitRatioStep))
+			}
+
+		case key.Matches(msg, keymap.ToggleFolderScope):
+			if m.focusedView == listFocused {
+				m.toggleFolderScope()
+			}
+
+		case key.Matches(msg, keymap.Random):
+			if m.focusedView == listFocused {
+				return m.openRandomNote()
+			}
+
+		case key.Matches(msg, keymap.AppendTodo):
+			if m.view != listView && !m.noteView.isEditing() {
+				return m.appendTodo()
+			}
+
+		case key.Matches(msg, keymap.Scratch):
+			if !m.noteView.isEditing() {
+				return m.openScratch()
+			}
+
+		case key.Matches(msg, keymap.ContentSearch):
+			if m.focusedView == listFocused && m.view != noteView {
+				m.list.ResetFilter()
+				return m, m.contentSearch.open()
+			}
+
+		case key.Matches(msg, keymap.Duplicate):
+			if m.focusedView == listFocused {
+				return m.duplicateNote()
+			}
+
+		case key.Matches(msg, keymap.CycleSortOrder):
+			if m.focusedView == listFocused {
+				return m.cycleSortOrder()
+			}
+
+		case key.Matches(msg, keymap.Undo):
+			if m.focusedView == listFocused {
+				return m.undo()
+			}
+
+		case key.Matches(msg, keymap.Archive):
+			if m.focusedView == listFocused {
+				return m.archiveNote()
+			}
+
+		case key.Matches(msg, keymap.TogglePin):
+			if m.focusedView == listFocused {
+				return m.togglePin()
+			}
+
+		case key.Matches(msg, keymap.Command):
+			if m.focusedView == listFocused && m.view != noteView {
+				return m, m.cmdInput.open()
+			}
+
 		case key.Matches(msg, keymap.New):
 			if m.noteView.isEditing() {
 				break
@@ -286,7 +697,7 @@ func (m ManagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			m.store.SetCurrentNoteName(selected)
 			width, height := m.getAvailableSizes()
-			m.noteView.setSize(width-min(width/2, minListWidth), height)
+			m.noteView.setSize(width-m.listWidth(width), height)
 			m.noteView.updateContent()
 
 		case noteFocused:
@@ -318,12 +729,23 @@ func (m ManagerModel) View() string {
 		return m.addNote.View()
 	}
 
+	if len(m.store.GetNotes()) == 0 {
+		return emptyStateView(m.width, m.height-lipgloss.Height(m.statusBarView())) + "\n" + m.statusBarView()
+	}
+
 	switch m.view {
 	case listView:
 		return viewPadding.Render(m.list.View()) + "\n" + m.statusBarView()
 
 	case noteView:
-		return m.noteView.View()
+		view := m.noteView.View()
+
+		// the confirmation takes the place of the last line of the full screen note
+		if m.showQuitConfirmation {
+			view = view[:max(strings.LastIndex(view, "\n"), 0)] + "\n" + m.statusBarView()
+		}
+
+		return view
 
 	case splitView:
 		return m.getSplitView()
@@ -335,11 +757,11 @@ func (m ManagerModel) View() string {
 
 func (m ManagerModel) getSplitView() string {
 	horizontalFrameSize := viewPadding.GetHorizontalFrameSize()
-	horizontalFrameBorderSize := activeBorder.GetHorizontalFrameSize()
+	horizontalFrameBorderSize := activeBorder().GetHorizontalFrameSize()
 
 	availableWidth := m.width - horizontalFrameSize
 
-	listWidth := min(minListWidth, availableWidth/2) - horizontalFrameBorderSize*2 - splitViewSeparatorWidth
+	listWidth := m.listWidth(availableWidth) - horizontalFrameBorderSize*2 - splitViewSeparatorWidth
 	noteWidth := availableWidth - listWidth - horizontalFrameBorderSize*2 - splitViewSeparatorWidth
 
 	var joinedContent string
@@ -347,11 +769,11 @@ func (m ManagerModel) getSplitView() string {
 	if m.focusedView == listFocused {
 		joinedContent = lipgloss.JoinHorizontal(
 			lipgloss.Left,
-			activeBorder.
+			activeBorder().
 				Width(listWidth).
 				Render(m.list.View()),
 			splitViewSeparator,
-			inactiveBorder.
+			inactiveBorder().
 				Width(noteWidth).
 				Height(m.list.Height()).
 				Render(m.noteView.View()),
@@ -359,11 +781,11 @@ func (m ManagerModel) getSplitView() string {
 	} else {
 		joinedContent = lipgloss.JoinHorizontal(
 			lipgloss.Left,
-			inactiveBorder.
+			inactiveBorder().
 				Width(listWidth).
 				Render(m.list.View()),
 			splitViewSeparator,
-			activeBorder.
+			activeBorder().
 				Width(noteWidth).
 				Height(m.list.Height()).
 				Render(m.noteView.View()),
@@ -380,6 +802,14 @@ func (m ManagerModel) getViewInCmdMode() string {
 }
 
 func (m ManagerModel) statusBarView() string {
+	if m.showQuitConfirmation {
+		return lipgloss.NewStyle().Margin(0, 2).Render(m.quitConfirmation.View())
+	}
+
+	if m.confirmBulkDelete {
+		return styles.Warning.Margin(0, 2).Render(fmt.Sprintf("Delete %d selected notes? (y/n)", len(m.selected)))
+	}
+
 	if m.error != nil {
 		return styles.Error.Margin(0, 2).Render(m.error.Error())
 	}
@@ -388,6 +818,14 @@ func (m ManagerModel) statusBarView() string {
 		return styles.Success.Margin(0, 2).Render(m.successMessage)
 	}
 
+	if m.cmdInput.active {
+		return lipgloss.NewStyle().Margin(0, 2).Render(m.cmdInput.View())
+	}
+
+	if m.contentSearch.active {
+		return lipgloss.NewStyle().Margin(0, 2).Render(m.contentSearch.View())
+	}
+
 	if m.list.FilterState() == list.Filtering {
 		m.help.Keys.ShortHelpBindings = []key.Binding{
 			keymap.Cancel,
@@ -407,101 +845,1002 @@ func (m ManagerModel) statusBarView() string {
 		return m.help.View()
 	}
 
-	return lipgloss.NewStyle().Margin(0, 2).Render(m.help.View())
+	position := m.positionView()
+	if position == "" {
+		return lipgloss.NewStyle().Margin(0, 2).Render(m.help.View())
+	}
+
+	helpView := lipgloss.NewStyle().Margin(0, 2).Render(m.help.View())
+
+	// drops the last key bindings until the position fits on the right
+	for len(m.help.Keys.ShortHelpBindings) > 1 && lipgloss.Width(helpView)+lipgloss.Width(position)+2 > m.width {
+		m.help.Keys.ShortHelpBindings = m.help.Keys.ShortHelpBindings[:len(m.help.Keys.ShortHelpBindings)-1]
+		helpView = lipgloss.NewStyle().Margin(0, 2).Render(m.help.View())
+	}
+
+	gap := max(m.width-lipgloss.Width(helpView)-lipgloss.Width(position)-2, 1)
+
+	return helpView + strings.Repeat(" ", gap) + position
 }
 
-func processNotes(notes []note.Note) []list.Item {
-	items := make([]list.Item, len(notes))
+// positionView shows the position of the selected note in the filtered list, as "N of M"
+func (m ManagerModel) positionView() string {
+	total := len(m.list.VisibleItems())
+	if total == 0 {
+		return ""
+	}
 
-	for i, n := range notes {
-		items[i] = item{
-			title: n.Name,
-			desc:  fmt.Sprintf("Last modified: %s", n.UpdatedAt.Format("02/01/2006 15:04")),
+	return styles.Subtext0.Render(fmt.Sprintf("%d of %d", m.list.Index()+1, total))
+}
+
+// visibleNotes returns the notes shown in the list,
+// limited to the scoped folder when folder scope is on
+func (m ManagerModel) visibleNotes() []note.Note {
+	notes := m.store.GetNotes()
+
+	if m.folderScoped {
+		notes = slices.DeleteFunc(slices.Clone(notes), func(n note.Note) bool {
+			return n.Folder != m.folderScope
+		})
+	}
+
+	if query := m.contentSearch.query(); query != "" {
+		matches := make(map[string]bool)
+		for _, n := range m.store.Search(query) {
+			matches[n.Name] = true
 		}
+
+		notes = slices.DeleteFunc(slices.Clone(notes), func(n note.Note) bool {
+			return !matches[n.Name]
+		})
 	}
 
-	return items
+	return notes
 }
 
-func (m *ManagerModel) handleWindowSize(msg tea.WindowSizeMsg) {
-	if msg.Width < 2*minListWidth {
-		switch m.view {
-		case splitView:
-			m.view = listView
-		case listView:
-			m.view = splitView
+// listItems builds the list items of the visible notes. While searching
+// the contents, each item describes where the query matched.
+func (m ManagerModel) listItems() []list.Item {
+	notes := sortNotes(m.visibleNotes(), m.sortOrder)
+	items := processNotes(notes, m.sortOrder)
+
+	for i, it := range items {
+		if it, ok := it.(item); ok && m.selected[it.title] {
+			it.selected = true
+			items[i] = it
 		}
 	}
 
-	m.width, m.height = msg.Width, msg.Height
+	if query := m.contentSearch.query(); query != "" {
+		for i, n := range notes {
+			if snippet := note.Snippet(n.Body(), query, snippetRadius); snippet != "" {
+				it := newItem(n)
+				it.desc = snippet
+				it.selected = m.selected[n.Name]
+				items[i] = it
+			}
+		}
+	}
 
-	availableWidth, availableHeight := m.getAvailableSizes()
+	return items
+}
 
-	m.help.SetSize(msg.Width, msg.Height)
+// updateListTitle describes the folder scope and content search in the list title
+func (m *ManagerModel) updateListTitle() {
+	title := "Notes"
 
-	if m.view == listView {
-		m.list.SetSize(availableWidth, availableHeight)
-		m.help.SetSize(msg.Width, msg.Height)
+	if m.folderScoped {
+		title = "Notes in " + utils.Ternary(m.folderScope == "", "/", m.folderScope+"/")
 	}
 
-	if m.view == noteView {
-		m.noteView.setSize(msg.Width, msg.Height)
+	if query := m.contentSearch.query(); query != "" {
+		title += fmt.Sprintf(" matching \"%s\"", query)
 	}
 
-	if m.view == splitView {
-		listWidth := min(availableWidth/2, minListWidth)
+	m.list.Title = title
+}
 
-		// Set list dimensions
-		m.list.SetHeight(availableHeight)
-		m.list.SetWidth(listWidth)
+// applyContentSearch narrows the list to the notes matching the content query
+func (m *ManagerModel) applyContentSearch() {
+	m.updateListTitle()
+	m.list.SetItems(m.listItems())
 
-		// Set note view dimensions
-		m.noteView.setSize(availableWidth-listWidth, availableHeight)
+	if current, ok := m.store.GetCurrentNote(); !ok || !m.selectNote(current.Name) {
+		m.list.ResetSelected()
+
+		if it, ok := m.list.SelectedItem().(item); ok {
+			m.store.SetCurrentNoteName(it.title)
+		}
 	}
+
+	m.noteView.updateContent()
 }
 
-func (m ManagerModel) handleEditorClose(isNew bool) (ManagerModel, tea.Cmd) {
-	notes, err := m.store.LoadNotes()
-	if err != nil {
-		return m, dispatch(cmdErrorMsg(err))
+func (m *ManagerModel) toggleFolderScope() {
+	m.folderScoped = !m.folderScoped
+	m.folderScope = ""
+
+	if current, ok := m.store.GetCurrentNote(); ok {
+		m.folderScope = current.Folder
 	}
 
-	m.list.SetItems(processNotes(notes))
+	m.updateListTitle()
 
-	m.noteView.updateContent()
+	m.list.ResetFilter()
+	m.list.SetItems(m.listItems())
 
-	if _, ok := m.store.GetCurrentNote(); ok {
-		// there seems to be a bug in bubbletea that causes the filter to not
-		// preserve the selected item after the list is updated
-		// reset the filter until a better solution is found
-		m.list.ResetFilter()
+	if current, ok := m.store.GetCurrentNote(); !ok || !m.selectNote(current.Name) {
+		m.list.ResetSelected()
 	}
+}
 
-	if m.store.IsFirstNote() || isNew {
-		m.list.ResetSelected()
+func processNotes(notes []note.Note, order note.SortOrder) []list.Item {
+	notes = sortNotes(notes, order)
+	items := make([]list.Item, len(notes))
+
+	for i, n := range notes {
+		items[i] = newItem(n)
 	}
 
-	return m, tea.Sequence(
-		m.dispatchWindowSizeMsg(),
-		tea.EnableMouseCellMotion,
-	)
+	return items
 }
 
-func (m ManagerModel) handleQuit() (ManagerModel, tea.Cmd) {
-	if m.help.FullView {
-		m.help.FullView = false
-		return m, dispatch(help.FullViewToggledMsg{})
+func newItem(n note.Note) item {
+	return item{
+		title:       n.Name,
+		displayName: n.Title(),
+		desc:        fmt.Sprintf("Last modified: %s", formatModified(n.UpdatedAt)),
+		pinned:      n.Pinned,
 	}
+}
 
-	if m.view == noteView {
-		m.view = splitView
-		m.focusedView = listFocused
-		m.noteView.fullScreen = false
-		m.noteView.help.FullView = false
-		return m, m.dispatchWindowSizeMsg()
+// formatModified formats when a note was last modified,
+// relative to now when relative_time is enabled
+func formatModified(t time.Time) string {
+	if config.GetRelativeTime() {
+		return utils.RelativeTime(t, time.Now())
 	}
 
-	return m, tea.Quit
+	return t.Format(config.GetDateFormat())
+}
+
+// sortNotes returns a sorted copy of notes, with the pinned ones first
+func sortNotes(notes []note.Note, order note.SortOrder) []note.Note {
+	notes = slices.Clone(notes)
+	note.SortNotes(notes, order)
+
+	return pinnedFirst(notes)
+}
+
+// pinnedFirst moves the pinned notes above the others, keeping their order
+func pinnedFirst(notes []note.Note) []note.Note {
+	notes = slices.Clone(notes)
+
+	slices.SortStableFunc(notes, func(a, b note.Note) int {
+		switch {
+		case a.Pinned == b.Pinned:
+			return 0
+		case a.Pinned:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	return notes
+}
+
+func (m *ManagerModel) handleWindowSize(msg tea.WindowSizeMsg) {
+	if m.view != noteView {
+		m.view = utils.Ternary(msg.Width < 2*m.minListWidth, listView, splitView)
+	}
+
+	if compact := msg.Width < m.minListWidth; compact != m.compactList {
+		m.compactList = compact
+		m.list.SetDelegate(newListDelegate(compact))
+	}
+
+	m.width, m.height = msg.Width, msg.Height
+
+	availableWidth, availableHeight := m.getAvailableSizes()
+
+	m.help.SetSize(msg.Width, msg.Height)
+
+	if m.view == listView {
+		m.list.SetSize(availableWidth, availableHeight)
+		m.help.SetSize(msg.Width, msg.Height)
+	}
+
+	if m.view == noteView {
+		m.noteView.setSize(msg.Width, msg.Height)
+	}
+
+	if m.view == splitView {
+		listWidth := m.listWidth(availableWidth)
+
+		// Set list dimensions
+		m.list.SetHeight(availableHeight)
+		m.list.SetWidth(listWidth)
+
+		// Set note view dimensions
+		m.noteView.setSize(availableWidth-listWidth, availableHeight)
+	}
+}
+
+// listWidth returns the width of the list pane in the split view,
+// the configured or resized fraction of the available width when set
+func (m ManagerModel) listWidth(availableWidth int) int {
+	if m.splitRatio <= 0 {
+		return min(availableWidth/2, m.minListWidth)
+	}
+
+	return int(float64(availableWidth) * m.splitRatio)
+}
+
+// resizeList grows the list pane of the split view, or shrinks it
+// when delta is negative, and lays out both panes again
+func (m ManagerModel) resizeList(delta float64) (ManagerModel, tea.Cmd) {
+	availableWidth, _ := m.getAvailableSizes()
+	if availableWidth <= 0 {
+		return m, nil
+	}
+
+	ratio := m.splitRatio
+	if ratio <= 0 {
+		ratio = float64(m.listWidth(availableWidth)) / float64(availableWidth)
+	}
+
+	m.splitRatio = min(max(ratio+delta, minSplitRatio), maxSplitRatio)
+
+	return m, m.dispatchWindowSizeMsg()
+}
+
+func (m ManagerModel) handleEditorClose(isNew bool) (ManagerModel, tea.Cmd) {
+	if _, err := m.store.LoadNotes(); err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	m.list.SetItems(m.listItems())
+
+	m.noteView.updateContent()
+
+	if _, ok := m.store.GetCurrentNote(); ok {
+		// there seems to be a bug in bubbletea that causes the filter to not
+		// preserve the selected item after the list is updated
+		// reset the filter until a better solution is found
+		m.list.ResetFilter()
+	}
+
+	if m.store.IsFirstNote() || isNew {
+		m.list.ResetSelected()
+	}
+
+	cmds := []tea.Cmd{m.dispatchWindowSizeMsg(), tea.EnableMouseCellMotion}
+
+	if current, ok := m.store.GetCurrentNote(); ok && !isNew {
+		ops := note.LineDiff(m.contentBeforeEditor, current.Content)
+
+		if inserted, deleted := note.LineChanges(ops); inserted+deleted > 0 {
+			m.noteView.diff.set(current.Name, ops)
+			cmds = append(cmds, dispatch(cmdSuccessMsg(fmt.Sprintf("Edited %s: +%d -%d lines, :diff to review", current.Name, inserted, deleted))))
+		}
+	}
+
+	return m, tea.Sequence(cmds...)
+}
+
+// selectNote moves the list selection to the note with the given name
+func (m *ManagerModel) selectNote(name string) bool {
+	for i, listItem := range m.list.VisibleItems() {
+		if it, ok := listItem.(item); ok && it.title == name {
+			m.list.Select(i)
+			m.store.SetCurrentNoteName(name)
+			return true
+		}
+	}
+
+	return false
+}
+
+// switchProfile loads the notes of the profile's storage in place of the
+// current ones and remembers it for the next runs
+func (m ManagerModel) switchProfile(name string) (ManagerModel, tea.Cmd) {
+	if m.store.IsEncrypted() {
+		return m, dispatch(cmdErrorMsg(fmt.Errorf("encrypted notes are unlocked on startup, restart with --profile %s", name)))
+	}
+
+	storage, err := config.GetProfileStorage(name)
+	if err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	if info, err := os.Stat(storage); err != nil || !info.IsDir() {
+		return m, dispatch(cmdErrorMsg(fmt.Errorf("the notes directory %s of profile %s doesn't exist", storage, name)))
+	}
+
+	// the storage is only switched for the rest of the run once its notes loaded
+	if _, err := m.store.SwitchStorage(storage); err != nil {
+		return m, dispatch(cmdErrorMsg(fmt.Errorf("failed to load the notes of %s: %w", name, err)))
+	}
+
+	if err := config.UseProfile(name); err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	m.recentNotes = nil
+	m.lastAction = nil
+
+	result := dispatch(cmdSuccessMsg(fmt.Sprintf("Switched to profile %s", name)))
+	if err := m.reset(); err != nil {
+		result = dispatch(cmdErrorMsg(err))
+	}
+
+	if err := config.SetProfile(name); err != nil {
+		result = dispatch(cmdErrorMsg(fmt.Errorf("failed to remember the profile: %w", err)))
+	}
+
+	return m, tea.Batch(result, m.dispatchWindowSizeMsg())
+}
+
+// trackRecentNote moves the current note to the front of the recent notes
+// when the selection changed to another note
+func (m *ManagerModel) trackRecentNote() {
+	current, ok := m.store.GetCurrentNote()
+	if !ok || (len(m.recentNotes) > 0 && m.recentNotes[0] == current.Name) {
+		return
+	}
+
+	m.recentNotes = pushRecent(m.recentNotes, current.Name)
+}
+
+// openRecentNotes opens the quick switcher with the notes opened before
+// the current one, leaving out those deleted or renamed since
+func (m *ManagerModel) openRecentNotes() {
+	var names []string
+
+	for _, name := range m.recentNotes[min(1, len(m.recentNotes)):] {
+		if _, ok := m.store.ResolveName(name); ok {
+			names = append(names, name)
+		}
+	}
+
+	m.noteView.recent.open(names)
+}
+
+// gotoNote selects the nth note of the list as it's filtered, counting from 1
+func (m ManagerModel) gotoNote(n int) (ManagerModel, tea.Cmd) {
+	items := m.list.VisibleItems()
+	if n < 1 || n > len(items) {
+		return m, dispatch(cmdErrorMsg(fmt.Errorf("there is no note %d, the list has %d", n, len(items))))
+	}
+
+	it, ok := items[n-1].(item)
+	if !ok {
+		return m, nil
+	}
+
+	m.list.Select(n - 1)
+	m.store.SetCurrentNoteName(it.title)
+	m.noteView.updateContent()
+
+	return m, nil
+}
+
+func (m ManagerModel) openRandomNote() (ManagerModel, tea.Cmd) {
+	randomNote, ok := m.store.Random()
+	if !ok {
+		return m, dispatch(cmdErrorMsg(errors.New("there are no notes to pick from")))
+	}
+
+	if !m.selectNote(randomNote.Name) {
+		m.list.ResetFilter()
+		m.selectNote(randomNote.Name)
+	}
+
+	m.noteView.updateContent()
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Opened \"%s\"", randomNote.Name)))
+}
+
+// openLink selects the note linked with [[name]], by its name or an alias
+func (m ManagerModel) openLink(name string) (ManagerModel, tea.Cmd) {
+	resolved, ok := m.store.ResolveName(name)
+	if !ok {
+		return m, dispatch(cmdErrorMsg(fmt.Errorf("note %s not found", name)))
+	}
+
+	if !m.selectNote(resolved) {
+		m.list.ResetFilter()
+		m.selectNote(resolved)
+	}
+
+	m.noteView.updateContent()
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Opened \"%s\"", resolved)))
+}
+
+// saveAsTemplate copies the current note into the templates directory
+func (m ManagerModel) saveAsTemplate() (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, dispatch(cmdErrorMsg(errors.New("there is no note to save as a template")))
+	}
+
+	name, err := m.store.SaveAsTemplate(current.Name)
+	if err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Copied note \"%s\" to template \"%s\"", current.Name, name)))
+}
+
+// createFromTemplate creates a note from the template and selects it
+func (m ManagerModel) createFromTemplate(template string) (ManagerModel, tea.Cmd) {
+	name, err := m.store.CreateNoteFromTemplate(template)
+	if err != nil && !commitFailed(err) {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	if _, loadErr := m.store.LoadNotes(); loadErr != nil {
+		return m, dispatch(cmdErrorMsg(loadErr))
+	}
+
+	m.list.SetItems(m.listItems())
+
+	if !m.selectNote(name) {
+		m.list.ResetFilter()
+		m.selectNote(name)
+	}
+
+	m.noteView.updateContent()
+
+	if err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Created note \"%s\" from template \"%s\"", name, template)))
+}
+
+// extractLines creates a note from a range of lines of the selected note
+// and selects it
+func (m ManagerModel) extractLines(start, end int, name string) (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, nil
+	}
+
+	if m.noteView.hasChanges() {
+		return m, dispatch(cmdErrorMsg(errors.New("save or discard your changes before extracting lines")))
+	}
+
+	created, err := m.store.ExtractLines(start, end, name)
+	if err != nil && !commitFailed(err) {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	if _, loadErr := m.store.LoadNotes(); loadErr != nil {
+		return m, dispatch(cmdErrorMsg(loadErr))
+	}
+
+	m.list.SetItems(m.listItems())
+
+	if !m.selectNote(created) {
+		m.list.ResetFilter()
+		m.selectNote(created)
+	}
+
+	m.noteView.updateContent()
+
+	if err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Extracted lines %d-%d of \"%s\" into \"%s\"", start, end, current.Name, created)))
+}
+
+// duplicateNote copies the selected note and selects the copy
+func (m ManagerModel) duplicateNote() (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, nil
+	}
+
+	duplicate, err := m.store.Duplicate(current.Name)
+	if err != nil && !commitFailed(err) {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	m.list.SetItems(m.listItems())
+
+	if !m.selectNote(duplicate.Name) {
+		m.list.ResetFilter()
+		m.selectNote(duplicate.Name)
+	}
+
+	m.noteView.updateContent()
+
+	if err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Duplicated \"%s\" as \"%s\"", current.Name, duplicate.Name)))
+}
+
+// undoableAction is the last delete or rename, which ctrl+z reverts
+type undoableAction struct {
+	// deleted holds the note as it was before being deleted
+	deleted *note.Note
+
+	previousName string
+	renamedTo    string
+}
+
+// undo reverts the last delete, moving the note back out of the trash,
+// or the last rename. Only one action is remembered.
+func (m ManagerModel) undo() (ManagerModel, tea.Cmd) {
+	action := m.lastAction
+	if action == nil {
+		return m, dispatch(cmdErrorMsg(errors.New("nothing to undo")))
+	}
+
+	m.lastAction = nil
+
+	var (
+		name    string
+		message string
+		err     error
+	)
+
+	if action.deleted != nil {
+		// restoring the note from the trash keeps when it was created and its pin
+		err = m.store.Undelete(action.deleted.Name)
+		if current, ok := m.store.GetCurrentNote(); ok {
+			name = current.Name
+		}
+		message = fmt.Sprintf("Restored deleted note \"%s\"", name)
+	} else {
+		var renamed note.Note
+		renamed, err = m.store.RenameNote(action.renamedTo, action.previousName)
+		name = renamed.Name
+		message = fmt.Sprintf("Renamed \"%s\" back to \"%s\"", action.renamedTo, name)
+	}
+
+	if err != nil && !commitFailed(err) {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	if action.deleted == nil {
+		m.renameSelected(action.renamedTo, name)
+	}
+
+	if _, loadErr := m.store.LoadNotes(); loadErr != nil {
+		return m, dispatch(cmdErrorMsg(loadErr))
+	}
+
+	m.list.SetItems(m.listItems())
+
+	if !m.selectNote(name) {
+		m.list.ResetFilter()
+		m.selectNote(name)
+	}
+
+	m.noteView.updateContent()
+
+	if err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	return m, dispatch(cmdSuccessMsg(message))
+}
+
+// archiveNote moves the selected note into the archive and selects the next one
+func (m ManagerModel) archiveNote() (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, nil
+	}
+
+	if err := m.store.Archive(current.Name); err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	m.list.RemoveItem(m.list.Index())
+
+	if it, ok := m.list.SelectedItem().(item); ok {
+		m.store.SetCurrentNoteName(it.title)
+	}
+
+	m.noteView.updateContent()
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Archived \"%s\"", current.Name)))
+}
+
+// mergeNote appends the source note to the selected note and deletes it
+func (m ManagerModel) mergeNote(source string) (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, nil
+	}
+
+	if m.noteView.hasChanges() {
+		return m, dispatch(cmdErrorMsg(errors.New("save or discard your changes before merging")))
+	}
+
+	if name, ok := m.store.ResolveName(source); ok {
+		source = name
+	}
+
+	err := m.store.Merge(current.Name, source)
+	if err != nil && !commitFailed(err) {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	m.list.SetItems(m.listItems())
+	m.selectNote(current.Name)
+	m.noteView.updateContent()
+
+	if err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Merged \"%s\" into \"%s\"", source, current.Name)))
+}
+
+// cycleSortOrder switches the list to the next sort order and remembers it
+func (m ManagerModel) cycleSortOrder() (ManagerModel, tea.Cmd) {
+	m.sortOrder = m.sortOrder.Next()
+
+	m.list.SetItems(m.listItems())
+
+	if current, ok := m.store.GetCurrentNote(); ok {
+		m.selectNote(current.Name)
+	}
+
+	if err := config.SetSortOrder(string(m.sortOrder)); err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Sorted by %s", m.sortOrder)))
+}
+
+// renameSelected keeps a renamed note selected under its new name
+func (m *ManagerModel) renameSelected(from, to string) {
+	if m.selected[from] {
+		delete(m.selected, from)
+		m.selected[to] = true
+	}
+}
+
+// toggleSelection marks the note under the cursor for bulk actions, or unmarks it
+func (m *ManagerModel) toggleSelection() {
+	it, ok := m.list.SelectedItem().(item)
+	if !ok {
+		return
+	}
+
+	if m.selected[it.title] {
+		delete(m.selected, it.title)
+	} else {
+		m.selected[it.title] = true
+	}
+
+	it.selected = m.selected[it.title]
+	m.list.SetItem(m.list.Index(), it)
+}
+
+// deleteSelected moves every selected note to the trash
+func (m ManagerModel) deleteSelected() (ManagerModel, tea.Cmd) {
+	var deleted int
+	var errs []error
+
+	for name := range m.selected {
+		if err := m.store.Delete(name); err != nil && !commitFailed(err) {
+			errs = append(errs, err)
+			continue
+		}
+
+		delete(m.selected, name)
+		deleted++
+	}
+
+	// a single delete can be undone, not a bulk one
+	m.lastAction = nil
+
+	m.list.SetItems(m.listItems())
+	if it, ok := m.list.SelectedItem().(item); ok {
+		m.store.SetCurrentNoteName(it.title)
+	}
+	m.noteView.updateContent()
+
+	if len(errs) > 0 {
+		return m, dispatch(cmdErrorMsg(errors.Join(errs...)))
+	}
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Moved %d %s to the trash", deleted, utils.Ternary(deleted == 1, "note", "notes"))))
+}
+
+// copyPlainText copies the current note to the clipboard without its markdown
+func (m ManagerModel) copyPlainText() (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, nil
+	}
+
+	if err := m.store.CopyPlainText(current.Content); err != nil {
+		return m, dispatch(cmdErrorMsg(fmt.Errorf("failed to copy %s: %w", current.Name, err)))
+	}
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Copied %s as plain text to the clipboard", current.Name)))
+}
+
+// addTags tags the current note with the tags picked among the suggestions
+func (m ManagerModel) addTags(tags []string) (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, nil
+	}
+
+	if m.noteView.hasChanges() {
+		return m, dispatch(cmdErrorMsg(errors.New("save or discard your changes before tagging")))
+	}
+
+	for _, tag := range tags {
+		if err := m.store.AddTag(current.Name, tag); err != nil && !commitFailed(err) {
+			return m, dispatch(cmdErrorMsg(err))
+		}
+	}
+
+	m.list.SetItems(m.listItems())
+	m.selectNote(current.Name)
+	m.noteView.updateContent()
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Tagged %s with #%s", current.Name, strings.Join(tags, " #"))))
+}
+
+// tagNotes adds the tag to the selected notes, or to the current note
+// when none is selected, or removes it from them
+func (m ManagerModel) tagNotes(tag string, remove bool) (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, nil
+	}
+
+	if m.noteView.hasChanges() {
+		return m, dispatch(cmdErrorMsg(errors.New("save or discard your changes before tagging")))
+	}
+
+	names := slices.Collect(maps.Keys(m.selected))
+	if len(names) == 0 {
+		names = []string{current.Name}
+	}
+
+	var errs []error
+
+	for _, name := range names {
+		var err error
+		if remove {
+			err = m.store.RemoveTag(name, tag)
+		} else {
+			err = m.store.AddTag(name, tag)
+		}
+
+		if err != nil && !commitFailed(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	m.list.SetItems(m.listItems())
+	m.selectNote(current.Name)
+	m.noteView.updateContent()
+
+	if len(errs) > 0 {
+		return m, dispatch(cmdErrorMsg(errors.Join(errs...)))
+	}
+
+	noun := utils.Ternary(len(names) == 1, "note", "notes")
+
+	if remove {
+		return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Removed #%s from %d %s", tag, len(names), noun)))
+	}
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf("Tagged %d %s with #%s", len(names), noun, tag)))
+}
+
+// togglePin pins the selected note to the top of the list, or unpins it
+func (m ManagerModel) togglePin() (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, nil
+	}
+
+	if err := m.store.TogglePin(current.Name); err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	m.list.SetItems(m.listItems())
+	m.selectNote(current.Name)
+
+	message := utils.Ternary(current.Pinned, "Unpinned \"%s\"", "Pinned \"%s\"")
+
+	return m, dispatch(cmdSuccessMsg(fmt.Sprintf(message, current.Name)))
+}
+
+// appendTodo adds an empty task at the end of the current note
+// and starts editing it
+func (m ManagerModel) appendTodo() (ManagerModel, tea.Cmd) {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return m, nil
+	}
+
+	if m.noteView.hasChanges() {
+		return m, dispatch(cmdErrorMsg(errors.New("save or discard your changes before adding a todo")))
+	}
+
+	err := m.store.Append(todoItem)
+	if err != nil && !commitFailed(err) {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	m.list.SetItems(m.listItems())
+	m.selectNote(current.Name)
+
+	m.focusedView = noteFocused
+
+	if err != nil {
+		return m, tea.Batch(m.noteView.editAtEnd(), dispatch(cmdErrorMsg(err)))
+	}
+
+	return m, m.noteView.editAtEnd()
+}
+
+// openScratch selects the scratch note, creating it the first time,
+// and opens it in the editor
+func (m ManagerModel) openScratch() (ManagerModel, tea.Cmd) {
+	if m.noteView.hasChanges() {
+		return m, dispatch(cmdErrorMsg(errors.New("save or discard your changes before opening the scratch note")))
+	}
+
+	var commitErr error
+
+	// an alias named after the scratch note doesn't count
+	if name, ok := m.store.ResolveName(note.ScratchNote); !ok || name != note.ScratchNote {
+		if err := m.store.Create(note.ScratchNote, ""); err != nil {
+			if !commitFailed(err) {
+				return m, dispatch(cmdErrorMsg(err))
+			}
+
+			commitErr = err
+		}
+
+		if _, err := m.store.LoadNotes(); err != nil {
+			return m, dispatch(cmdErrorMsg(err))
+		}
+
+		m.list.SetItems(m.listItems())
+	}
+
+	if !m.selectNote(note.ScratchNote) {
+		m.folderScoped = false
+		m.contentSearch.clear()
+		m.updateListTitle()
+		m.list.ResetFilter()
+		m.list.SetItems(m.listItems())
+		m.selectNote(note.ScratchNote)
+	}
+
+	// the list alone has no room for the note, which opens full screen
+	if m.view == listView {
+		m.view = noteView
+		m.noteView.fullScreen = true
+		m.noteView.setSize(m.width, m.height)
+	}
+
+	m.focusedView = noteFocused
+	cmd := m.noteView.editAtEnd()
+
+	if commitErr != nil {
+		return m, tea.Batch(cmd, dispatch(cmdErrorMsg(commitErr)))
+	}
+
+	return m, cmd
+}
+
+// reset clears any filter, sort order and selection applied to the list and
+// returns the manager to its default state. The default order is remembered
+// in place of the one cycled to, which only fails to be saved.
+func (m *ManagerModel) reset() error {
+	m.folderScoped = false
+	m.folderScope = ""
+	m.contentSearch.clear()
+	m.sortOrder = note.SortByModified
+	clear(m.selected)
+	m.updateListTitle()
+
+	m.list.ResetFilter()
+	m.list.SetItems(m.listItems())
+	m.list.ResetSelected()
+
+	m.view = splitView
+	m.focusedView = listFocused
+	m.noteView.fullScreen = false
+	m.noteView.blur()
+
+	if item, ok := m.list.SelectedItem().(item); ok {
+		m.store.SetCurrentNoteName(item.title)
+	}
+
+	m.noteView.updateContent()
+
+	if err := config.SetSortOrder(string(m.sortOrder)); err != nil {
+		return fmt.Errorf("failed to save the sort order: %w", err)
+	}
+
+	return nil
+}
+
+func (m ManagerModel) saveNote(content string) (ManagerModel, tea.Cmd) {
+	err := m.store.UpdateCurrentNoteContent(content)
+	if err != nil && !commitFailed(err) {
+		m.error = fmt.Errorf("failed to save note: %w", err)
+		m.successMessage = ""
+		return m, nil
+	}
+
+	m.successMessage = utils.Ternary(err == nil, "Note saved", "")
+	m.error = nil
+	m.noteView.updateContent()
+
+	if m.view == splitView {
+		m.list.SetItems(m.listItems())
+		m.list.ResetSelected()
+	}
+
+	// the note is saved even when committing it failed
+	if err != nil {
+		return m, dispatch(cmdErrorMsg(err))
+	}
+
+	return m, dispatchClearMsg()
+}
+
+func (m ManagerModel) handleQuit() (ManagerModel, tea.Cmd) {
+	if m.help.FullView {
+		m.help.FullView = false
+		return m, dispatch(help.FullViewToggledMsg{})
+	}
+
+	if m.view == noteView {
+		m.view = splitView
+		m.focusedView = listFocused
+		m.noteView.fullScreen = false
+		m.noteView.help.FullView = false
+		return m, m.dispatchWindowSizeMsg()
+	}
+
+	if m.noteView.hasChanges() {
+		return m.confirmQuit()
+	}
+
+	return m, m.quit()
+}
+
+// confirmQuit asks whether to discard the unsaved changes of the note
+// instead of quitting straight away
+func (m ManagerModel) confirmQuit() (ManagerModel, tea.Cmd) {
+	m.showQuitConfirmation = true
+	return m, m.quitConfirmation.Focus()
+}
+
+// quit remembers the layout for the next session before quitting
+func (m ManagerModel) quit() tea.Cmd {
+	_ = state.Save(state.State{
+		NoteFocused: m.view == splitView && m.focusedView == noteFocused,
+		LineNumbers: m.noteView.lineNumbers,
+		SplitRatio:  utils.Ternary(m.splitRatio != config.GetSplitRatio(), m.splitRatio, 0),
+	})
+
+	return tea.Quit
 }
 
 func (m ManagerModel) handleFullScreen() (ManagerModel, tea.Cmd) {
@@ -538,7 +1877,13 @@ func (m *ManagerModel) triggerNoteEditor() (bool, tea.Cmd) {
 		return false, nil
 	}
 
+	// the external editor would open the encrypted file
+	if m.store.IsEncrypted() {
+		return true, dispatch(cmdErrorMsg(errors.New("encrypted notes can't be opened in an external editor")))
+	}
+
 	if note, ok := m.store.GetCurrentNote(); ok {
+		m.contentBeforeEditor = note.Content
 		notePath := m.store.GetNotePath(note.Name)
 		execCmd := tea.ExecProcess(exec.Command(m.store.GetEditor(), notePath), func(error) tea.Msg {
 			return editorClosedMsg{}
@@ -559,7 +1904,7 @@ func (m ManagerModel) getAvailableSizes() (int, int) {
 
 	statusBarHeight := lipgloss.Height(m.statusBarView())
 
-	availableHeight := m.height - v - statusBarHeight - activeBorder.GetBorderBottomSize()
+	availableHeight := m.height - v - statusBarHeight - activeBorder().GetBorderBottomSize()
 	availableWidth := m.width - h
 
 	return availableWidth, availableHeight
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -18,15 +19,38 @@ type cmdErrorMsg error
 type cmdAbortMsg struct{}
 
 type cmdNoteRenamedMsg struct {
-	note note.Note
+	note         note.Note
+	previousName string
 }
 
-type cmdNoteDeletedMsg struct{}
+type cmdNoteDeletedMsg struct {
+	note note.Note
+}
 
 type noteAddedMsg struct{}
 
 type changesDiscardedMsg struct{}
 
+type reloadNoteMsg struct{}
+
+type overwriteNoteMsg struct {
+	content string
+}
+
+type autosaveMsg struct{}
+
+// autosave schedules the next draft of the edited note,
+// never when the interval is zero
+func autosave(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return autosaveMsg{}
+	})
+}
+
 func dispatch(msg tea.Msg) tea.Cmd {
 	return func() tea.Msg {
 		return msg
@@ -38,3 +62,9 @@ func dispatchClearMsg() tea.Cmd {
 		return clearMsg{}
 	})
 }
+
+// commitFailed reports whether err only means that git_auto_commit could
+// not commit a change, the change itself having been saved
+func commitFailed(err error) bool {
+	return errors.Is(err, note.ErrAutoCommit)
+}
@@ -5,20 +5,24 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/ionut-t/coffee/markdown"
 	editor "github.com/ionut-t/goeditor/adapter-bubbletea"
 	"github.com/ionut-t/goeditor/core"
+	"github.com/ionut-t/notes/internal/config"
 	"github.com/ionut-t/notes/internal/help"
 	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/internal/state"
 	"github.com/ionut-t/notes/internal/utils"
+	"github.com/ionut-t/notes/markdown"
 	"github.com/ionut-t/notes/note"
 	"github.com/ionut-t/notes/styles"
+	"github.com/mattn/go-runewidth"
 )
 
 type NoteModel struct {
@@ -35,8 +39,44 @@ type NoteModel struct {
 	confirmation     *huh.Confirm
 	showConfirmation bool
 
-	previousCursorPosition core.Position
-	currentNoteName        string
+	reloadConfirmation     *huh.Confirm
+	showReloadConfirmation bool
+	deleteConfirmation     *huh.Confirm
+	showDeleteConfirmation bool
+	draftConfirmation      *huh.Confirm
+	showDraftConfirmation  bool
+	draftContent           string
+	autosaveInterval       time.Duration
+	externalContent        string
+	pendingContent         string
+	// externalChanges is the word diff of the edited content against the disk,
+	// worked out once when the reload is asked for rather than on every render
+	externalChanges []note.DiffOp
+
+	currentNoteName string
+
+	// where each note was left, restored when switching back to it
+	cursorPositions map[string]core.Position
+	scrollOffsets   map[string]int
+
+	outline outlineModel
+	links   linksModel
+	diff    diffModel
+	tags    tagSuggestionsModel
+	recent  recentNotesModel
+
+	// line numbers toggled with "V", by note name, over the configured mode
+	defaultLineNumbers markdown.LineNumberMode
+	lineNumbers        map[string]bool
+
+	// row of the rendered note highlighted while reading, 0 when disabled
+	focusLine int
+
+	// width the rendered note wraps at, centred in wider panes, 0 for the pane width
+	maxRenderWidth int
+
+	// shows the source of the note with its whitespace revealed instead of rendering it
+	revealWhitespace bool
 }
 
 func NewNoteModel(store *note.Store, width, height int) NoteModel {
@@ -44,9 +84,41 @@ func NewNoteModel(store *note.Store, width, height int) NoteModel {
 
 	vp := viewport.New(width, height)
 
-	md := markdown.New()
+	md := markdown.New(note.Body(), width)
+	md.SetFirstLine(note.BodyLine())
+	// an unset or unknown theme follows the terminal background
+	if err := md.SetTheme(config.GetTheme()); err != nil {
+		md.SetCatppuccinTheme(utils.Ternary(lipgloss.HasDarkBackground(), "dark", "light"))
+	}
+	md.SetNumberHeaders(config.GetNumberHeaders())
+	md.SetClickableLinks(config.GetClickableLinks())
+	md.SetNoColor(config.GetNoColor())
+	md.SetTabWidth(config.GetTabWidth())
+
+	defaultLineNumbers := markdown.LineNumbersOff
+	if mode, err := markdown.ParseLineNumberMode(config.GetLineNumbers()); err == nil {
+		defaultLineNumbers = mode
+	}
+
+	lineNumbers := state.Load().LineNumbers
+	if lineNumbers == nil {
+		lineNumbers = make(map[string]bool)
+	}
+
+	var initError error
+
+	if err := md.SetCodeThemes(config.GetCodeThemes()); err != nil {
+		initError = err
+	}
+
+	if config.GetSpellcheck() {
+		dictionary, err := markdown.LoadDictionary(config.GetSpellcheckDictionary(), config.GetSpellcheckIgnore())
+		if err != nil {
+			initError = fmt.Errorf("failed to load spellcheck dictionary: %w", err)
+		}
 
-	md.Render(note.Content)
+		md.SetDictionary(dictionary)
+	}
 
 	textEditor := editor.New(80, 20)
 	textEditor.SetCursorMode(editor.CursorBlink)
@@ -62,14 +134,7 @@ func NewNoteModel(store *note.Store, width, height int) NoteModel {
 		keymap.ExternalEditor,
 	}
 
-	helpMenu.Keys.FullHelpBindings = []key.Binding{
-		keymap.Up,
-		keymap.Down,
-		keymap.ExternalEditor,
-		keymap.New,
-		keymap.Quit,
-		keymap.Help,
-	}
+	helpMenu.Keys.FullHelpBindings = keymap.NoteBindings
 
 	helpMenu.SetSize(width, height)
 
@@ -84,6 +149,39 @@ func NewNoteModel(store *note.Store, width, height int) NoteModel {
 
 	confirmation.WithTheme(styles.ThemeCatppuccin())
 
+	reloadConfirmation := huh.NewConfirm().
+		Title("This note was changed on disk. Reload it and discard your edits?").
+		Affirmative("Reload").
+		Negative("Keep mine")
+
+	reloadConfirmation.WithKeyMap(&huh.KeyMap{
+		Confirm: huh.NewDefaultKeyMap().Confirm,
+	})
+
+	reloadConfirmation.WithTheme(styles.ThemeCatppuccin())
+
+	deleteConfirmation := huh.NewConfirm().
+		Affirmative("Delete").
+		Negative("Keep")
+
+	deleteConfirmation.WithKeyMap(&huh.KeyMap{
+		Confirm: huh.NewDefaultKeyMap().Confirm,
+	})
+
+	deleteConfirmation.WithTheme(styles.ThemeCatppuccin())
+
+	draftConfirmation := huh.NewConfirm().
+		Title("Restore the unsaved changes?").
+		Description("Autosaved before the last session ended.").
+		Affirmative("Restore").
+		Negative("Discard")
+
+	draftConfirmation.WithKeyMap(&huh.KeyMap{
+		Confirm: huh.NewDefaultKeyMap().Confirm,
+	})
+
+	draftConfirmation.WithTheme(styles.ThemeCatppuccin())
+
 	return NoteModel{
 		store:           store,
 		viewport:        vp,
@@ -95,6 +193,18 @@ func NewNoteModel(store *note.Store, width, height int) NoteModel {
 		confirmation:    confirmation,
 		showEditor:      true,
 		currentNoteName: note.Name,
+		cursorPositions: make(map[string]core.Position),
+		scrollOffsets:   make(map[string]int),
+		error:           initError,
+
+		reloadConfirmation: reloadConfirmation,
+		deleteConfirmation: deleteConfirmation,
+		draftConfirmation:  draftConfirmation,
+		autosaveInterval:   config.GetAutosaveInterval(),
+		defaultLineNumbers: defaultLineNumbers,
+		lineNumbers:        lineNumbers,
+		focusLine:          config.GetFocusLine(),
+		maxRenderWidth:     config.GetMaxRenderWidth(),
 	}
 }
 
@@ -103,7 +213,42 @@ func (m NoteModel) Init() tea.Cmd {
 }
 
 func (m NoteModel) View() string {
-	view := utils.Ternary(m.showEditor, m.editor.View(), m.viewport.View())
+	view := utils.Ternary(m.showEditor, m.editor.View(), m.highlightFocusLine(m.viewport.View()))
+
+	if m.outline.active {
+		view = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.outline.View(m.viewport.Width, m.viewport.Height))
+	}
+
+	if m.links.active {
+		view = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.links.View(m.viewport.Width, m.viewport.Height))
+	}
+
+	if m.tags.active {
+		view = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.tags.View(m.viewport.Width, m.viewport.Height))
+	}
+
+	if m.diff.active {
+		view = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.diff.View(m.viewport.Width, m.viewport.Height))
+	}
+
+	if m.recent.active {
+		view = lipgloss.NewStyle().
+			Width(m.viewport.Width).
+			Height(m.viewport.Height).
+			Render(m.recent.View(m.viewport.Width, m.viewport.Height))
+	}
 
 	if m.showConfirmation {
 		view = lipgloss.JoinVertical(
@@ -113,6 +258,18 @@ func (m NoteModel) View() string {
 		)
 	}
 
+	if m.showReloadConfirmation {
+		view = m.externalChangesView()
+	}
+
+	if m.showDeleteConfirmation {
+		view = m.deleteConfirmationView()
+	}
+
+	if m.showDraftConfirmation {
+		view = m.draftView()
+	}
+
 	if !m.fullScreen {
 		return view
 	}
@@ -142,7 +299,12 @@ func (m NoteModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case editor.DeleteFileMsg:
-		return m.executeNoteDeletion()
+		if !config.GetConfirmDelete() {
+			return m.executeNoteDeletion()
+		}
+
+		m.confirmDeletion()
+		return m, nil
 
 	case editor.RenameMsg:
 		return m.renameNote(msg.FileName)
@@ -169,6 +331,48 @@ func (m NoteModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				m.editor.Focus()
 			}
+
+			if m.showReloadConfirmation {
+				reload := m.reloadConfirmation.GetValue().(bool)
+				content := m.pendingContent
+
+				m.hideReloadConfirmation()
+
+				if reload {
+					return m, dispatch(reloadNoteMsg{})
+				}
+
+				return m, dispatch(overwriteNoteMsg{content})
+			}
+
+			if m.showDraftConfirmation {
+				restore := m.draftConfirmation.GetValue().(bool)
+				content := m.draftContent
+
+				m.hideDraftConfirmation()
+
+				if restore {
+					return m, dispatch(overwriteNoteMsg{content})
+				}
+
+				if err := m.discardDraft(); err != nil {
+					return m, dispatch(cmdErrorMsg(err))
+				}
+
+				return m, nil
+			}
+
+			if m.showDeleteConfirmation {
+				confirmed := m.deleteConfirmation.GetValue().(bool)
+
+				m.hideDeleteConfirmation()
+
+				if confirmed {
+					return m.executeNoteDeletion()
+				}
+
+				return m, nil
+			}
 		}
 	}
 
@@ -186,6 +390,18 @@ func (m NoteModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		confirmation, cmd := m.confirmation.Update(msg)
 		m.confirmation = confirmation.(*huh.Confirm)
 		cmds = append(cmds, cmd)
+	} else if m.showReloadConfirmation {
+		confirmation, cmd := m.reloadConfirmation.Update(msg)
+		m.reloadConfirmation = confirmation.(*huh.Confirm)
+		cmds = append(cmds, cmd)
+	} else if m.showDraftConfirmation {
+		confirmation, cmd := m.draftConfirmation.Update(msg)
+		m.draftConfirmation = confirmation.(*huh.Confirm)
+		cmds = append(cmds, cmd)
+	} else if m.showDeleteConfirmation {
+		confirmation, cmd := m.deleteConfirmation.Update(msg)
+		m.deleteConfirmation = confirmation.(*huh.Confirm)
+		cmds = append(cmds, cmd)
 	} else {
 		editorModel, cmd := m.editor.Update(msg)
 		m.editor = editorModel.(editor.Model)
@@ -212,19 +428,36 @@ func (m NoteModel) statusBarView() string {
 
 	separator := styles.Surface0.Render(" | ")
 
-	note, _ := m.store.GetCurrentNote()
+	current, _ := m.store.GetCurrentNote()
+
+	name := styles.Primary.Background(bg).Render(current.Name)
 
-	name := styles.Primary.Background(bg).Render(note.Name)
+	if m.hasChanges() {
+		name += styles.Overlay0.Background(bg).Render(" ●")
+	}
+
+	dateFormat := config.GetDateFormat()
 
-	modifiedDate := styles.Accent.Background(bg).Render("Last Modified " + note.UpdatedAt.Format("02/01/2006 15:04"))
+	createdDate := styles.Accent.Background(bg).Render("Created " + current.CreatedAt.Format(dateFormat))
+
+	modifiedDate := styles.Accent.Background(bg).Render("Last Modified " + formatModified(current.UpdatedAt))
 
 	noteInfo := styles.Surface0.Padding(0, 1).Render(
-		name + separator + modifiedDate,
+		name + separator + createdDate + separator + modifiedDate,
 	)
 
 	lineNumbers := styles.Info.Background(bg).Render(strconv.Itoa(m.getLineNumbers()))
 
-	scroll := styles.Surface0.Render(fmt.Sprintf("%3.f%%", m.viewport.ScrollPercent()*100))
+	words := m.getWordCount()
+	countsText := fmt.Sprintf("%d words, %d chars", words, m.getCharCount())
+
+	if m.isEditing() {
+		countsText += fmt.Sprintf(", %d min read", note.ReadingTime(words))
+	}
+
+	counts := styles.Subtext1.Background(bg).Render(countsText) + separator
+
+	scroll := styles.Surface0.Render(fmt.Sprintf("%4s", m.scrollPosition()))
 
 	helpText := styles.Info.Background(bg).PaddingRight(1).Render("? Help")
 
@@ -235,6 +468,13 @@ func (m NoteModel) statusBarView() string {
 		lipgloss.Width(helpText) -
 		2*lipgloss.Width(separator)
 
+	// the counts are the first to go on narrow terminals
+	if displayedInfoWidth < lipgloss.Width(counts) {
+		counts = ""
+	}
+
+	displayedInfoWidth -= lipgloss.Width(counts)
+
 	spaces := styles.Surface0.Render(strings.Repeat(" ", max(0, displayedInfoWidth)))
 
 	return styles.Surface0.Width(m.width).Padding(0, 0).Render(
@@ -242,6 +482,7 @@ func (m NoteModel) statusBarView() string {
 			lipgloss.Right,
 			noteInfo,
 			spaces,
+			counts,
 			lineNumbers,
 			separator,
 			scroll,
@@ -251,6 +492,45 @@ func (m NoteModel) statusBarView() string {
 	)
 }
 
+// scrollPosition describes where the viewport is in the note the way less does:
+// "All" when the note fits on screen, "Top" and "Bot" at the extremes
+// and a percentage in between
+func (m NoteModel) scrollPosition() string {
+	switch {
+	case m.viewport.TotalLineCount() <= m.viewport.Height:
+		return "All"
+	case m.viewport.AtTop():
+		return "Top"
+	case m.viewport.AtBottom():
+		return "Bot"
+	default:
+		return fmt.Sprintf("%.f%%", m.viewport.ScrollPercent()*100)
+	}
+}
+
+// countedContent returns the body the counts are based on, without the
+// frontmatter as in notes stats and export. It includes the unsaved
+// changes while the editor is shown.
+func (m NoteModel) countedContent() string {
+	if m.showEditor || m.isEditing() {
+		return note.Note{Content: m.editor.GetCurrentContent()}.Body()
+	}
+
+	if current, ok := m.store.GetCurrentNote(); ok {
+		return current.Body()
+	}
+
+	return ""
+}
+
+func (m NoteModel) getWordCount() int {
+	return note.WordCount(m.countedContent())
+}
+
+func (m NoteModel) getCharCount() int {
+	return utf8.RuneCountInString(m.countedContent())
+}
+
 func (m NoteModel) getLineNumbers() int {
 	if note, ok := m.store.GetCurrentNote(); ok {
 		return len(strings.Split(note.Content, "\n"))
@@ -277,11 +557,10 @@ func (m *NoteModel) setSize(width, height int) {
 }
 
 func (m *NoteModel) updateContent() {
-	m.previousCursorPosition = m.editor.GetCursorPosition()
+	m.rememberPosition()
 
 	m.viewport.Height = m.height
 	m.viewport.Width = m.width
-	m.viewport.SetYOffset(0)
 	m.editor.SetSize(m.width, m.height)
 	m.render()
 
@@ -289,26 +568,61 @@ func (m *NoteModel) updateContent() {
 	m.editor = texteditor.(editor.Model)
 }
 
+// rememberPosition saves the cursor and scroll position of the note
+// being left, to restore them when it is shown again
+func (m *NoteModel) rememberPosition() {
+	if m.currentNoteName == "" {
+		return
+	}
+
+	m.cursorPositions[m.currentNoteName] = m.editor.GetCursorPosition()
+	m.scrollOffsets[m.currentNoteName] = m.viewport.YOffset
+}
+
+// renderMarkdown renders the note, or its source with the whitespace revealed,
+// centred in the pane when max_render_width makes it narrower
+func (m *NoteModel) renderMarkdown() string {
+	rendered := m.markdown.Render()
+
+	if note, ok := m.store.GetCurrentNote(); ok && m.revealWhitespace {
+		rendered = markdown.RevealWhitespace(note.Content, m.markdown.TabWidth)
+	}
+
+	margin := (m.width - m.markdown.Width) / 2
+	if margin <= 0 {
+		return rendered
+	}
+
+	padding := strings.Repeat(" ", margin)
+	lines := strings.Split(rendered, "\n")
+
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = padding + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func (m *NoteModel) render() {
 	if note, ok := m.store.GetCurrentNote(); ok {
-		if out, err := m.markdown.Render(note.Content); err != nil {
-			m.error = fmt.Errorf("failed to render note content: %w", err)
-		} else {
-			m.viewport.SetContent(out)
-			m.viewport.YOffset = 0
-		}
+		m.markdown.Width = utils.Ternary(m.maxRenderWidth > 0, min(m.width, m.maxRenderWidth), m.width)
+		m.markdown.SetLineNumberMode(m.lineNumberMode(note.Name))
+		m.markdown.SetFirstLine(note.BodyLine())
+		m.markdown.SetContent(note.Body())
+		m.viewport.SetContent(m.renderMarkdown())
+		m.viewport.SetYOffset(m.scrollOffsets[note.Name])
 
 		m.editor.SetContent(note.Content)
 
-		var cursorPosition core.Position
-		if m.currentNoteName == note.Name {
-			cursorPosition = m.previousCursorPosition
-		} else {
-			cursorPosition = core.Position{Row: 0, Col: 0}
-		}
+		cursorPosition := m.cursorPositions[note.Name]
 
+		// the note may have been shortened since, by an external change
 		if err := m.editor.SetCursorPosition(cursorPosition.Row, cursorPosition.Col); err != nil {
-			m.error = fmt.Errorf("failed to set cursor position: %w", err)
+			if err := m.editor.SetCursorPosition(0, 0); err != nil {
+				m.error = fmt.Errorf("failed to set cursor position: %w", err)
+			}
 		}
 
 		m.currentNoteName = note.Name
@@ -318,6 +632,141 @@ func (m *NoteModel) render() {
 	}
 }
 
+// lineNumberMode returns the line numbers of the note: the configured mode,
+// unless they were toggled for it
+func (m NoteModel) lineNumberMode(name string) markdown.LineNumberMode {
+	show, ok := m.lineNumbers[name]
+	if !ok {
+		return m.defaultLineNumbers
+	}
+
+	if !show {
+		return markdown.LineNumbersOff
+	}
+
+	return utils.Ternary(m.defaultLineNumbers == markdown.LineNumbersOff, markdown.LineNumbersAll, m.defaultLineNumbers)
+}
+
+// toggleLineNumbers shows or hides the line numbers of the current note,
+// keeping the line at the top of the viewport in place although the
+// narrower text wraps differently
+func (m *NoteModel) toggleLineNumbers() {
+	note, ok := m.store.GetCurrentNote()
+	if !ok {
+		return
+	}
+
+	m.lineNumbers[note.Name] = m.markdown.LineNumbers == markdown.LineNumbersOff
+
+	line := m.markdown.SourceLine(m.viewport.YOffset)
+
+	m.markdown.SetLineNumberMode(m.lineNumberMode(note.Name))
+	m.viewport.SetContent(m.renderMarkdown())
+	m.viewport.SetYOffset(m.markdown.RenderedRow(line))
+}
+
+// toggleWhitespace switches between the rendered note and
+// its source with the spaces and tabs made visible
+func (m *NoteModel) toggleWhitespace() {
+	m.revealWhitespace = !m.revealWhitespace
+	m.viewport.SetContent(m.renderMarkdown())
+}
+
+// setTheme changes the theme of the rendered note and renders it again
+func (m *NoteModel) setTheme(name string) error {
+	if err := m.markdown.SetTheme(name); err != nil {
+		return err
+	}
+
+	m.updateContent()
+
+	return nil
+}
+
+// highlightFocusLine gives the focus row of the rendered note a background,
+// following the scroll position. The background is restored after every
+// reset of the styles on the line, which would otherwise clear it.
+func (m NoteModel) highlightFocusLine(view string) string {
+	row := m.focusLine - 1
+	lines := strings.Split(view, "\n")
+
+	if row < 0 || row >= len(lines) {
+		return view
+	}
+
+	marker := styles.Surface0.Render(" ")
+	background := marker[:strings.Index(marker, " ")]
+
+	// nothing to do when the styles render without colours
+	if background == "" {
+		return view
+	}
+
+	const reset = "\x1b[0m"
+
+	line := lines[row] + strings.Repeat(" ", max(m.viewport.Width-lipgloss.Width(lines[row]), 0))
+	lines[row] = background + strings.ReplaceAll(line, reset, reset+background) + reset
+
+	return strings.Join(lines, "\n")
+}
+
+// toggleOutline opens the list of headers of the rendered note, or closes it
+func (m *NoteModel) toggleOutline() {
+	if m.outline.active {
+		m.outline.close()
+		return
+	}
+
+	m.outline.open(m.markdown.Headings())
+}
+
+// toggleLinks opens the list of the notes linked from the rendered note
+// and of those linking back to it, or closes it
+func (m *NoteModel) toggleLinks() {
+	if m.links.active {
+		m.links.close()
+		return
+	}
+
+	m.links.open(m.markdown.WikiLinks(), m.store.Backlinks(m.currentNoteName))
+}
+
+// toggleSection collapses or expands the first section whose summary is
+// on screen, or else the section the top of the screen is in
+func (m *NoteModel) toggleSection() {
+	top, bottom := m.viewport.YOffset, m.viewport.YOffset+m.viewport.Height
+	line := -1
+
+	for _, section := range m.markdown.Sections() {
+		if section.Hidden {
+			continue
+		}
+
+		row := m.markdown.RenderedRow(section.Line)
+		if row >= bottom {
+			break
+		}
+
+		line = section.Line
+		if row >= top {
+			break
+		}
+	}
+
+	if line < 0 {
+		return
+	}
+
+	m.markdown.ToggleSection(line)
+	m.viewport.SetContent(m.renderMarkdown())
+	m.viewport.SetYOffset(top)
+}
+
+// jumpTo scrolls the rendered note to the given line of its content
+func (m *NoteModel) jumpTo(line int) {
+	m.viewport.SetYOffset(m.markdown.RenderedRow(line))
+}
+
 func (m *NoteModel) isEditing() bool {
 	return m.editor.IsInsertMode()
 }
@@ -341,7 +790,29 @@ func (m *NoteModel) toggleEdit() {
 	m.setSize(m.width, m.height)
 }
 
+// editAtEnd opens the editor in insert mode at the end of the note
+func (m *NoteModel) editAtEnd() tea.Cmd {
+	m.showEditor = true
+	m.updateContent()
+	m.setSize(m.width, m.height)
+
+	if err := m.editor.SetCursorPositionEnd(); err != nil {
+		m.error = fmt.Errorf("failed to set cursor position: %w", err)
+	}
+
+	m.editor.Focus()
+	m.editor.SetInsertMode()
+
+	return m.editor.CursorBlink()
+}
+
 func (m *NoteModel) focus() tea.Cmd {
+	m.offerDraft()
+
+	if m.showDraftConfirmation {
+		return nil
+	}
+
 	if m.showEditor {
 		m.editor.Focus()
 		return m.editor.CursorBlink()
@@ -365,27 +836,204 @@ func (m *NoteModel) confirm(show bool) {
 	m.setSize(m.width, m.height)
 }
 
+// confirmReload asks whether to reload a note that was changed on disk
+// while it was being edited, showing what differs from the in-memory version
+func (m *NoteModel) confirmReload(diskContent, editedContent string) {
+	m.externalContent = diskContent
+	m.pendingContent = editedContent
+	m.externalChanges = note.WordDiff(editedContent, diskContent)
+	m.showReloadConfirmation = true
+	m.reloadConfirmation.Focus()
+	m.editor.Blur()
+}
+
+func (m *NoteModel) hideReloadConfirmation() {
+	m.showReloadConfirmation = false
+	m.externalContent = ""
+	m.pendingContent = ""
+	m.externalChanges = nil
+	m.reloadConfirmation.Blur()
+	m.editor.Focus()
+}
+
+func (m NoteModel) externalChangesView() string {
+	var sb strings.Builder
+
+	for _, op := range m.externalChanges {
+		switch op.Kind {
+		case note.DiffInsert:
+			sb.WriteString(styles.Success.Underline(true).Render(op.Text))
+		case note.DiffDelete:
+			sb.WriteString(styles.Error.Strikethrough(true).Render(op.Text))
+		default:
+			sb.WriteString(styles.Subtext0.Render(op.Text))
+		}
+	}
+
+	legend := styles.Success.Render("+ on disk") + "  " + styles.Error.Render("- your version")
+	confirmation := m.reloadConfirmation.View()
+
+	diffHeight := max(m.height-lipgloss.Height(confirmation)-lipgloss.Height(legend)-1, 1)
+
+	diffLines := strings.Split(lipgloss.NewStyle().Width(m.width).Render(sb.String()), "\n")
+	if len(diffLines) > diffHeight {
+		diffLines = diffLines[:diffHeight]
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		legend,
+		strings.Join(diffLines, "\n"),
+		"",
+		confirmation,
+	)
+}
+
+// saveDraft writes the unsaved changes of the note to its draft
+func (m *NoteModel) saveDraft() tea.Cmd {
+	current, ok := m.store.GetCurrentNote()
+	if !ok || !m.hasChanges() {
+		return nil
+	}
+
+	if err := m.store.SaveDraft(current.Name, m.editor.GetCurrentContent()); err != nil {
+		return dispatch(cmdErrorMsg(fmt.Errorf("failed to save draft: %w", err)))
+	}
+
+	return nil
+}
+
+// discardDraft removes the draft of the current note
+func (m *NoteModel) discardDraft() error {
+	if current, ok := m.store.GetCurrentNote(); ok {
+		return m.store.DeleteDraft(current.Name)
+	}
+
+	return nil
+}
+
+// offerDraft asks whether to restore the draft left by a previous
+// session that ended before the changes to the note were saved
+func (m *NoteModel) offerDraft() {
+	current, ok := m.store.GetCurrentNote()
+	if !ok || m.hasChanges() || m.showDraftConfirmation {
+		return
+	}
+
+	draft, ok := m.store.GetDraft(current.Name)
+	if !ok {
+		return
+	}
+
+	m.draftContent = draft
+	m.draftConfirmation.Value(new(bool))
+	m.showDraftConfirmation = true
+	m.draftConfirmation.Focus()
+	m.editor.Blur()
+}
+
+func (m *NoteModel) hideDraftConfirmation() {
+	m.showDraftConfirmation = false
+	m.draftContent = ""
+	m.draftConfirmation.Blur()
+	m.editor.Focus()
+}
+
+func (m NoteModel) draftView() string {
+	confirmation := m.draftConfirmation.View()
+	height := max(m.height-lipgloss.Height(confirmation)-1, 1)
+
+	lines := strings.Split(lipgloss.NewStyle().Width(m.width).Render(m.draftContent), "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		confirmation,
+		"",
+		styles.Subtext0.Render(strings.Join(lines, "\n")),
+	)
+}
+
+// confirmDeletion asks whether to delete the current note,
+// previewing its first lines so it isn't mistaken for another
+func (m *NoteModel) confirmDeletion() {
+	current, ok := m.store.GetCurrentNote()
+	if !ok {
+		return
+	}
+
+	m.deleteConfirmation.Title(fmt.Sprintf("Delete \"%s\"?", current.Name))
+	m.deleteConfirmation.Value(new(bool))
+	m.showDeleteConfirmation = true
+	m.deleteConfirmation.Focus()
+	m.editor.Blur()
+}
+
+func (m *NoteModel) hideDeleteConfirmation() {
+	m.showDeleteConfirmation = false
+	m.deleteConfirmation.Blur()
+	m.editor.Focus()
+}
+
+func (m NoteModel) deleteConfirmationView() string {
+	current, _ := m.store.GetCurrentNote()
+
+	const previewLines = 3
+
+	lines := strings.Split(strings.TrimSpace(current.Body()), "\n")
+	if len(lines) > previewLines {
+		lines = lines[:previewLines]
+	}
+
+	for i, line := range lines {
+		lines[i] = runewidth.Truncate(line, m.width, "…")
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.deleteConfirmation.View(),
+		"",
+		styles.Overlay0.Render(strings.Join(lines, "\n")),
+	)
+}
+
 func (m NoteModel) executeNoteDeletion() (NoteModel, tea.Cmd) {
+	deleted, _ := m.store.GetCurrentNote()
+
 	err := m.store.DeleteCurrentNote()
-	if err != nil {
+	if err != nil && !commitFailed(err) {
 		return m, dispatch(cmdErrorMsg(err))
 	}
 
+	result := dispatch(cmdSuccessMsg("Note moved to the trash"))
+	if err != nil {
+		result = dispatch(cmdErrorMsg(err))
+	}
+
 	return m, tea.Sequence(
-		dispatch(cmdNoteDeletedMsg{}),
-		dispatch(cmdSuccessMsg("Note successfully deleted")),
+		dispatch(cmdNoteDeletedMsg{deleted}),
+		result,
 	)
 }
 
 func (m NoteModel) renameNote(name string) (NoteModel, tea.Cmd) {
+	previous, _ := m.store.GetCurrentNote()
+
 	note, err := m.store.RenameCurrentNote(name)
-	if err != nil {
+	if err != nil && !commitFailed(err) {
 		return m, dispatch(cmdErrorMsg(err))
 	}
 
+	result := dispatch(cmdSuccessMsg(fmt.Sprintf("Note renamed to \"%s\"", note.Name)))
+	if err != nil {
+		result = dispatch(cmdErrorMsg(err))
+	}
+
 	return m, tea.Sequence(
-		dispatch(cmdNoteRenamedMsg{note}),
-		dispatch(cmdSuccessMsg(fmt.Sprintf("Note renamed to \"%s\"", note.Name))),
+		dispatch(cmdNoteRenamedMsg{note: note, previousName: previous.Name}),
+		result,
 	)
 }
 
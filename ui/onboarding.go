@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ionut-t/notes/internal/config"
+	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/styles"
+)
+
+// Logo is the ASCII art name of the app, printed with the version
+const Logo = `
+   _   _    ___    _____   _____   ____  
+  | \ | |  / _ \  |_   _| | ____| / ___| 
+  |  \| | | | | |   | |   |  _|   \___ \ 
+  | |\  | | |_| |   | |   | |___   ___) |
+  |_| \_|  \___/    |_|   |_____| |____/                  	 
+`
+
+// emptyStateView welcomes to the app when there are no notes yet, centred
+// in the terminal. The logo is left out when the terminal is too small for it.
+func emptyStateView(width, height int) string {
+	var logo []string
+	for line := range strings.Lines(strings.Trim(Logo, "\n")) {
+		logo = append(logo, strings.TrimRight(line, " \t\n"))
+	}
+
+	newKey := keymap.New.Help().Key
+	helpKey := keymap.Help.Help().Key
+
+	blurb := []string{
+		styles.Text.Bold(true).Render("Welcome to notes"),
+		"",
+		styles.Subtext1.Render("Notes are markdown files kept in " + config.GetStorage()),
+		styles.Subtext1.Render("Write them in the built-in editor or in your own."),
+		"",
+		styles.Primary.Bold(true).Render(newKey) + styles.Subtext0.Render(" create your first note"),
+		styles.Primary.Bold(true).Render(helpKey) + styles.Subtext0.Render(" show all the keybindings"),
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Center, blurb...)
+
+	logoView := styles.Error.Render(strings.Join(logo, "\n"))
+	if lipgloss.Width(logoView) <= width && lipgloss.Height(logoView)+lipgloss.Height(content)+1 <= height {
+		content = lipgloss.JoinVertical(lipgloss.Center, logoView, "", content)
+	}
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, content)
+}
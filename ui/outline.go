@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/markdown"
+	"github.com/ionut-t/notes/styles"
+)
+
+// outlineJumpMsg asks the note view to scroll to the header on the given line
+type outlineJumpMsg struct {
+	line int
+}
+
+// outlineModel lists the headers of the note, indented by level,
+// to jump between its sections
+type outlineModel struct {
+	headings []markdown.Heading
+	cursor   int
+	active   bool
+}
+
+func (m *outlineModel) open(headings []markdown.Heading) {
+	m.headings = headings
+	m.cursor = 0
+	m.active = true
+}
+
+func (m *outlineModel) close() {
+	m.active = false
+}
+
+func (m outlineModel) Update(msg tea.Msg) (outlineModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, keymap.Cancel, keymap.Outline):
+		m.close()
+
+	case key.Matches(keyMsg, keymap.Up):
+		m.cursor = max(m.cursor-1, 0)
+
+	case key.Matches(keyMsg, keymap.Down):
+		m.cursor = min(m.cursor+1, len(m.headings)-1)
+
+	case key.Matches(keyMsg, keymap.RunCommand):
+		m.close()
+
+		if len(m.headings) > 0 {
+			return m, dispatch(outlineJumpMsg{line: m.headings[m.cursor].Line})
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the visible part of the list, scrolled to keep the cursor in view
+func (m outlineModel) View(width, height int) string {
+	if len(m.headings) == 0 {
+		return styles.Subtext0.Render("This note has no headers")
+	}
+
+	height = max(height-1, 1)
+	start := max(0, m.cursor-height+1)
+	end := min(len(m.headings), start+height)
+
+	minLevel := m.headings[0].Level
+	for _, h := range m.headings {
+		minLevel = min(minLevel, h.Level)
+	}
+
+	lines := []string{styles.Accent.Bold(true).Render("Outline")}
+
+	for i := start; i < end; i++ {
+		h := m.headings[i]
+		text := strings.Repeat("  ", h.Level-minLevel) + h.Text
+
+		if i == m.cursor {
+			lines = append(lines, styles.Primary.Bold(true).MaxWidth(width).Render("> "+text))
+		} else {
+			lines = append(lines, styles.Text.MaxWidth(width).Render("  "+text))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/styles"
+)
+
+// maxRecentNotes is how many of the previously opened notes the quick switcher offers
+const maxRecentNotes = 5
+
+// pushRecent moves the name to the front of the most recently used notes,
+// keeping the current note and the maxRecentNotes opened before it
+func pushRecent(recent []string, name string) []string {
+	recent = slices.DeleteFunc(recent, func(n string) bool {
+		return n == name
+	})
+
+	recent = append([]string{name}, recent...)
+
+	return recent[:min(len(recent), maxRecentNotes+1)]
+}
+
+// recentNotesModel lists the notes opened before the current one,
+// most recent first, to switch to one of them by its number
+type recentNotesModel struct {
+	names  []string
+	cursor int
+	active bool
+}
+
+func (m *recentNotesModel) open(names []string) {
+	m.names = names
+	m.cursor = 0
+	m.active = true
+}
+
+func (m *recentNotesModel) close() {
+	m.active = false
+}
+
+func (m recentNotesModel) Update(msg tea.Msg) (recentNotesModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	// the number of a note opens it straight away
+	if n, err := strconv.Atoi(keyMsg.String()); err == nil && n >= 1 && n <= len(m.names) {
+		m.close()
+		return m, dispatch(openLinkMsg{name: m.names[n-1]})
+	}
+
+	switch {
+	case key.Matches(keyMsg, keymap.Cancel, keymap.RecentNotes):
+		m.close()
+
+	case key.Matches(keyMsg, keymap.Up):
+		m.cursor = max(m.cursor-1, 0)
+
+	case key.Matches(keyMsg, keymap.Down):
+		m.cursor = max(min(m.cursor+1, len(m.names)-1), 0)
+
+	case key.Matches(keyMsg, keymap.RunCommand):
+		m.close()
+
+		if len(m.names) > 0 {
+			return m, dispatch(openLinkMsg{name: m.names[m.cursor]})
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the numbered notes, highlighting the one under the cursor
+func (m recentNotesModel) View(width, height int) string {
+	if len(m.names) == 0 {
+		return styles.Subtext0.Render("No other note was opened yet")
+	}
+
+	lines := []string{styles.Accent.Bold(true).Render("Recent notes")}
+
+	for i, name := range m.names {
+		entry := fmt.Sprintf("%d %s", i+1, name)
+
+		if i == m.cursor {
+			lines = append(lines, styles.Primary.Bold(true).MaxWidth(width).Render("> "+entry))
+		} else {
+			lines = append(lines, styles.Text.MaxWidth(width).Render("  "+entry))
+		}
+	}
+
+	return strings.Join(lines[:min(len(lines), max(height, 1))], "\n")
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ionut-t/notes/internal/keymap"
+	"github.com/ionut-t/notes/internal/utils"
+	"github.com/ionut-t/notes/styles"
+)
+
+// maxTagSuggestions is how many words of the note are suggested as tags
+const maxTagSuggestions = 8
+
+// addTagsMsg asks the manager to tag the current note
+type addTagsMsg struct {
+	tags []string
+}
+
+// tagSuggestionsModel shows the words suggested as tags for the note as
+// chips, to pick some of them with space and add them with enter
+type tagSuggestionsModel struct {
+	tags     []string
+	selected map[int]bool
+	cursor   int
+	active   bool
+}
+
+func (m *tagSuggestionsModel) open(tags []string) {
+	m.tags = tags
+	m.selected = make(map[int]bool)
+	m.cursor = 0
+	m.active = true
+}
+
+func (m *tagSuggestionsModel) close() {
+	m.active = false
+}
+
+func (m tagSuggestionsModel) Update(msg tea.Msg) (tagSuggestionsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, keymap.Cancel):
+		m.close()
+
+	case key.Matches(keyMsg, keymap.Left, keymap.Up):
+		m.cursor = max(m.cursor-1, 0)
+
+	case key.Matches(keyMsg, keymap.Right, keymap.Down):
+		m.cursor = min(m.cursor+1, len(m.tags)-1)
+
+	case key.Matches(keyMsg, keymap.ToggleSelect):
+		m.selected[m.cursor] = !m.selected[m.cursor]
+
+	case key.Matches(keyMsg, keymap.RunCommand):
+		m.close()
+
+		var tags []string
+		for i, tag := range m.tags {
+			if m.selected[i] {
+				tags = append(tags, tag)
+			}
+		}
+
+		// enter without a selection picks the chip under the cursor
+		if len(tags) == 0 && len(m.tags) > 0 {
+			tags = []string{m.tags[m.cursor]}
+		}
+
+		if len(tags) > 0 {
+			return m, dispatch(addTagsMsg{tags: tags})
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the suggestions as chips wrapped to the width,
+// highlighting the selected ones and the one under the cursor
+func (m tagSuggestionsModel) View(width, height int) string {
+	if len(m.tags) == 0 {
+		return styles.Subtext0.Render("No tags to suggest for this note")
+	}
+
+	chip := lipgloss.NewStyle().Padding(0, 1).MarginRight(1)
+
+	var rows []string
+	var row []string
+	rowWidth := 0
+
+	for i, tag := range m.tags {
+		style := chip.Inherit(styles.Surface0).Inherit(utils.Ternary(m.selected[i], styles.Success, styles.Text))
+		if i == m.cursor {
+			style = style.Bold(true).Underline(true)
+		}
+
+		rendered := style.Render(utils.Ternary(m.selected[i], "✓ #", "#") + tag)
+		if rowWidth > 0 && rowWidth+lipgloss.Width(rendered) > width {
+			rows = append(rows, strings.Join(row, ""))
+			row, rowWidth = nil, 0
+		}
+
+		row = append(row, rendered)
+		rowWidth += lipgloss.Width(rendered)
+	}
+
+	rows = append(rows, strings.Join(row, ""))
+
+	lines := []string{
+		styles.Accent.Bold(true).Render("Suggested tags"),
+		"",
+	}
+	lines = append(lines, rows...)
+	lines = append(lines, "", styles.Subtext0.Render("space select • enter add • esc cancel"))
+
+	if len(lines) > height {
+		lines = lines[:max(height, 1)]
+	}
+
+	return strings.Join(lines, "\n")
+}